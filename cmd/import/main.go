@@ -0,0 +1,66 @@
+// 从 Markdown 文件的 zip 压缩包导入文章，与 POST /api/v1/articles/import 相同，需要在项目根目录下运行以读取 conf/app.ini：
+//
+//	go run ./cmd/import -file posts.zip -user admin
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"gin-blog/models"
+	"gin-blog/pkg/gcache"
+	"gin-blog/pkg/gredis"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/service/article_service"
+	"gin-blog/service/tag_service"
+	"log"
+	"os"
+)
+
+func main() {
+	file := flag.String("file", "", "zip of markdown files with front matter")
+	user := flag.String("user", "", "created_by of the imported articles and tags")
+	flag.Parse()
+	if *file == "" || *user == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	setting.Setup()
+	models.Setup()
+	logging.Setup()
+	gredis.Setup()
+
+	//与服务使用相同的缓存，导入后服务中的文章、标签缓存随之失效
+	cache, err := gcache.New()
+	if err != nil {
+		log.Fatalf("gcache.New err: %v", err)
+	}
+	article_service.SetCache(cache)
+	tag_service.SetCache(cache)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("open %s err: %v", *file, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		log.Fatalf("stat %s err: %v", *file, err)
+	}
+
+	//命令行导入由管理员执行，不存在的标签自动创建
+	report, err := article_service.ImportMarkdown(f, info.Size(), *user, true)
+	if err != nil {
+		log.Fatalf("import err: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("encode report err: %v", err)
+	}
+	log.Printf("[info] created %d, skipped %d, failed %d", len(report.Created), len(report.Skipped), len(report.Failed))
+}
@@ -18,7 +18,6 @@ SET FOREIGN_KEY_CHECKS=0;
 DROP TABLE IF EXISTS `blog_article`;
 CREATE TABLE `blog_article` (
                                 `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
-                                `tag_id` int(10) unsigned DEFAULT '0' COMMENT '标签ID',
                                 `title` varchar(100) DEFAULT '' COMMENT '文章标题',
                                 `desc` varchar(255) DEFAULT '' COMMENT '简述',
                                 `content` text COMMENT '内容',
@@ -29,9 +28,41 @@ CREATE TABLE `blog_article` (
                                 `modified_by` varchar(255) DEFAULT '' COMMENT '修改人',
                                 `deleted_on` int(10) unsigned DEFAULT '0',
                                 `state` tinyint(3) unsigned DEFAULT '1' COMMENT '删除时间',
-                                PRIMARY KEY (`id`)
+                                `publish_at` int(10) unsigned DEFAULT '0' COMMENT '定时发布时间',
+                                `unpublish_at` int(10) unsigned DEFAULT '0' COMMENT '定时下线时间',
+                                PRIMARY KEY (`id`),
+                                KEY `idx_publish_at` (`publish_at`),
+                                KEY `idx_unpublish_at` (`unpublish_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='文章管理';
 
+-- ----------------------------
+-- Table structure for blog_article_revision
+-- ----------------------------
+DROP TABLE IF EXISTS `blog_article_revision`;
+CREATE TABLE `blog_article_revision` (
+                                         `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+                                         `article_id` int(10) unsigned NOT NULL COMMENT '文章ID',
+                                         `title` varchar(100) DEFAULT '' COMMENT '文章标题',
+                                         `desc` varchar(255) DEFAULT '' COMMENT '简述',
+                                         `content` longtext COMMENT '内容',
+                                         `cover_image_url` varchar(255) DEFAULT '' COMMENT '封面图片地址',
+                                         `created_by` varchar(100) DEFAULT '' COMMENT '该版本的修改人',
+                                         `created_on` int(10) unsigned DEFAULT '0' COMMENT '该版本的修改时间',
+                                         PRIMARY KEY (`id`),
+                                         KEY `idx_article_id` (`article_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='文章历史版本';
+
+-- ----------------------------
+-- Table structure for blog_article_tag
+-- ----------------------------
+DROP TABLE IF EXISTS `blog_article_tag`;
+CREATE TABLE `blog_article_tag` (
+                                    `article_id` int(10) unsigned NOT NULL COMMENT '文章ID',
+                                    `tag_id` int(10) unsigned NOT NULL COMMENT '标签ID',
+                                    PRIMARY KEY (`article_id`,`tag_id`),
+                                    KEY `idx_tag_id` (`tag_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='文章标签关联';
+
 -- ----------------------------
 -- Table structure for blog_auth
 -- ----------------------------
@@ -39,11 +70,73 @@ DROP TABLE IF EXISTS `blog_auth`;
 CREATE TABLE `blog_auth` (
                              `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
                              `username` varchar(50) DEFAULT '' COMMENT '账号',
-                             `password` varchar(50) DEFAULT '' COMMENT '密码',
-                             PRIMARY KEY (`id`)
-) ENGINE=InnoDB AUTO_INCREMENT=2 DEFAULT CHARSET=utf8;
+                             `password` varchar(255) DEFAULT '' COMMENT '密码哈希',
+                             `role` varchar(20) DEFAULT 'reader' COMMENT '角色 admin、editor、author、reader',
+                             `created_on` int(10) unsigned DEFAULT '0' COMMENT '创建时间',
+                             `modified_on` int(10) unsigned DEFAULT '0' COMMENT '修改时间',
+                             `deleted_on` int(10) unsigned DEFAULT '0' COMMENT '删除时间',
+                             PRIMARY KEY (`id`),
+                             UNIQUE KEY `uk_username` (`username`,`deleted_on`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- ----------------------------
+-- Table structure for blog_auth_setup
+-- ----------------------------
+DROP TABLE IF EXISTS `blog_auth_setup`;
+CREATE TABLE `blog_auth_setup` (
+                                   `id` int(10) unsigned NOT NULL,
+                                   PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='初始化标记';
+
+INSERT INTO `blog_auth_setup` (`id`) VALUES (1);
 
-INSERT INTO `blog_auth` (`id`, `username`, `password`) VALUES ('1', 'test', 'test123');
+-- ----------------------------
+-- Table structure for blog_job_run
+-- ----------------------------
+DROP TABLE IF EXISTS `blog_job_run`;
+CREATE TABLE `blog_job_run` (
+                                `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+                                `name` varchar(100) DEFAULT '' COMMENT '任务名称',
+                                `host` varchar(255) DEFAULT '' COMMENT '执行任务的主机',
+                                `trigger` varchar(20) DEFAULT '' COMMENT '触发方式 schedule、manual',
+                                `started_on` int(10) unsigned DEFAULT '0' COMMENT '开始时间',
+                                `duration` int(10) unsigned DEFAULT '0' COMMENT '耗时（毫秒）',
+                                `error` text COMMENT '错误信息，成功时为空',
+                                PRIMARY KEY (`id`),
+                                KEY `idx_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='定时任务执行记录';
+
+-- ----------------------------
+-- Table structure for blog_media
+-- ----------------------------
+DROP TABLE IF EXISTS `blog_media`;
+CREATE TABLE `blog_media` (
+                              `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+                              `hash` char(64) NOT NULL COMMENT '文件内容的SHA-256',
+                              `name` varchar(255) NOT NULL COMMENT '相对于ImageSavePath的保存路径',
+                              `filename` varchar(255) DEFAULT '' COMMENT '第一次上传时的文件名',
+                              `mime_type` varchar(50) DEFAULT '' COMMENT '文件类型',
+                              `size` int(10) unsigned DEFAULT '0' COMMENT '文件大小，单位字节',
+                              `width` int(10) unsigned DEFAULT '0' COMMENT '宽度，按EXIF方向摆正后',
+                              `height` int(10) unsigned DEFAULT '0' COMMENT '高度，按EXIF方向摆正后',
+                              `upload_count` int(10) unsigned DEFAULT '0' COMMENT '上传次数，重复上传相同的内容时增加，不是被文章引用的次数',
+                              `created_by` varchar(100) DEFAULT '' COMMENT '第一次上传的用户',
+                              `created_on` int(10) unsigned DEFAULT '0' COMMENT '第一次上传时间',
+                              `modified_on` int(10) unsigned DEFAULT '0' COMMENT '最近一次上传时间',
+                              PRIMARY KEY (`id`),
+                              UNIQUE KEY `uk_hash` (`hash`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='上传的媒体文件';
+
+-- ----------------------------
+-- Table structure for blog_media_tag
+-- ----------------------------
+DROP TABLE IF EXISTS `blog_media_tag`;
+CREATE TABLE `blog_media_tag` (
+                                  `media_id` int(10) unsigned NOT NULL,
+                                  `tag_id` int(10) unsigned NOT NULL,
+                                  PRIMARY KEY (`media_id`,`tag_id`),
+                                  KEY `idx_tag_id` (`tag_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8 COMMENT='媒体文件标签关联';
 
 -- ----------------------------
 -- Table structure for blog_tag
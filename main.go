@@ -4,10 +4,16 @@ import (
 	"fmt"
 	_ "gin-blog/docs"
 	"gin-blog/models"
+	"gin-blog/pkg/gcache"
 	"gin-blog/pkg/gredis"
 	"gin-blog/pkg/logging"
 	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"gin-blog/pkg/upload"
 	"gin-blog/routers"
+	"gin-blog/scheduler"
+	"gin-blog/service/article_service"
+	"gin-blog/service/tag_service"
 	"github.com/gin-gonic/gin"
 	"log"
 	"net/http"
@@ -18,6 +24,25 @@ func init() {
 	models.Setup()
 	logging.Setup()
 	gredis.Setup()
+	if err := storage.Setup(); err != nil {
+		log.Fatalf("storage.Setup err: %v", err)
+	}
+	if err := upload.Setup(); err != nil {
+		log.Fatalf("upload.Setup err: %v", err)
+	}
+
+	cache, err := gcache.New()
+	if err != nil {
+		log.Fatalf("gcache.New err: %v", err)
+	}
+	article_service.SetCache(cache)
+	tag_service.SetCache(cache)
+
+	index, err := article_service.NewIndex()
+	if err != nil {
+		log.Fatalf("article_service.NewIndex err: %v", err)
+	}
+	article_service.SetIndex(index)
 }
 
 func main() {
@@ -37,6 +62,10 @@ func main() {
 		MaxHeaderBytes: maxHeaderBytes,
 	}
 
+	//定时任务随服务一起启动，是否调度由 [scheduler] Enabled 控制
+	scheduler.Setup()
+	defer scheduler.Stop()
+
 	log.Printf("[info] start http server listening %s", endPoint)
 
 	server.ListenAndServe()
@@ -0,0 +1,99 @@
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPCache 为 GET 请求的成功响应加上 Cache-Control 和 ETag，
+// 客户端带着相同的 If-None-Match 再次请求时返回 304，不再重复传输响应内容
+func HTTPCache(maxAge time.Duration) gin.HandlerFunc {
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(maxAge/time.Second))
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.status != http.StatusOK {
+			c.Writer.WriteHeader(w.status)
+			c.Writer.Write(w.body.Bytes())
+			return
+		}
+
+		sum := sha1.Sum(w.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+		header := c.Writer.Header()
+		header.Set("ETag", etag)
+		header.Set("Cache-Control", cacheControl)
+
+		if matchETag(c.GetHeader("If-None-Match"), etag) {
+			//304 不能带响应内容
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			c.Writer.WriteHeader(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+
+		c.Writer.WriteHeader(http.StatusOK)
+		c.Writer.Write(w.body.Bytes())
+	}
+}
+
+// If-None-Match 可能包含多个 ETag，也可能是弱校验的 W/"..."
+func matchETag(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == etag || v == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bufferedWriter 先缓存响应内容，计算出 ETag 后再写给客户端
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
@@ -2,12 +2,17 @@ package jwt
 
 import (
 	"gin-blog/pkg/err"
+	"gin-blog/pkg/logging"
 	"gin-blog/pkg/util"
+	"gin-blog/service/auth_service"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"time"
 )
 
+// ClaimsKey 校验通过后 token 中的信息保存在 gin.Context 中的键名
+const ClaimsKey = "claims"
+
 func JWT() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var code int
@@ -19,10 +24,18 @@ func JWT() gin.HandlerFunc {
 			code = err.INVALID_PARAMS
 		} else {
 			claims, e := util.ParseToken(token)
-			if e != nil {
+			if e != nil || claims.Id == "" {
 				code = err.ERROR_AUTH_CHECK_TOKEN_FAIL
 			} else if time.Now().Unix() > claims.ExpiresAt {
 				code = err.ERROR_AUTH_CHECK_TOKEN_TIMEOUT
+			} else if revoked, e := auth_service.IsTokenRevoked(claims); e != nil {
+				//无法确认是否已注销时拒绝请求
+				logging.Warn(e)
+				code = err.ERROR_AUTH_CHECK_TOKEN_FAIL
+			} else if revoked {
+				code = err.ERROR_AUTH_TOKEN_REVOKED
+			} else {
+				c.Set(ClaimsKey, claims)
 			}
 		}
 
@@ -40,3 +53,14 @@ func JWT() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// GetClaims 获取当前登录用户的 token 信息，未经过 JWT 中间件时返回 nil
+func GetClaims(c *gin.Context) *util.Claims {
+	if v, ok := c.Get(ClaimsKey); ok {
+		if claims, ok := v.(*util.Claims); ok {
+			return claims
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package jwt
+
+import (
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/rbac"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Permission 要求当前用户的角色拥有指定权限，必须放在 JWT() 之后使用
+func Permission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetClaims(c)
+		if claims == nil || !rbac.HasPermission(claims.Role, permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code": err.ERROR_AUTH_PERMISSION_DENIED,
+				"msg":  err.GetMsg(err.ERROR_AUTH_PERMISSION_DENIED),
+				"data": nil,
+			})
+
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
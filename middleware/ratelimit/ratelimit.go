@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"gin-blog/middleware/jwt"
+	"gin-blog/pkg/err"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// window 一个用户在当前时间窗口内的请求次数
+type window struct {
+	start time.Time
+	count int
+}
+
+// PerUser 限制每个用户在 period 内最多请求 limit 次，超过时返回 429，必须放在 JWT() 之后使用。
+// 计数保存在进程内，多实例部署时每个实例分别计数；limit 小于等于 0 时不限制
+func PerUser(limit int, period time.Duration) gin.HandlerFunc {
+	var (
+		mu      sync.Mutex
+		windows = make(map[string]*window)
+	)
+
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		var username string
+		if claims := jwt.GetClaims(c); claims != nil {
+			username = claims.Username
+		}
+
+		now := time.Now()
+		mu.Lock()
+		//清理已经过期的窗口，防止用户数量增长后一直占用内存
+		for k, w := range windows {
+			if now.Sub(w.start) >= period {
+				delete(windows, k)
+			}
+		}
+		w, ok := windows[username]
+		if !ok {
+			w = &window{start: now}
+			windows[username] = w
+		}
+		w.count++
+		allowed := w.count <= limit
+		mu.Unlock()
+
+		if !allowed {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"code": err.TOO_MANY_REQUESTS,
+				"msg":  err.GetMsg(err.TOO_MANY_REQUESTS),
+				"data": nil,
+			})
+
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
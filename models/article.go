@@ -1,12 +1,15 @@
 package models
 
-import "github.com/jinzhu/gorm"
+import (
+	"fmt"
+	"gin-blog/pkg/markdown"
+	"github.com/jinzhu/gorm"
+)
 
 type Article struct {
 	Model
 
-	TagID int `json:"tag_id" gorm:"index"`
-	Tag   Tag `json:"tag"`
+	Tags []Tag `json:"tags" gorm:"many2many:article_tag;"`
 
 	Title         string `json:"title"`
 	Desc          string `json:"desc"`
@@ -15,6 +18,32 @@ type Article struct {
 	CreatedBy     string `json:"created_by"`
 	ModifiedBy    string `json:"modified_by"`
 	State         int    `json:"state"`
+	//定时发布、下线的时间，0表示不设置，到达时间后由定时任务修改State并清零
+	PublishAt   int `json:"publish_at"`
+	UnpublishAt int `json:"unpublish_at"`
+
+	//由Content渲染得到，不保存到数据库，只在文章详情中返回
+	ContentHtml string              `json:"content_html,omitempty" gorm:"-"`
+	Toc         []*markdown.TocItem `json:"toc,omitempty" gorm:"-"`
+}
+
+//IsPublished 文章在now时刻是否对读者可见，与Published的查询条件保持一致
+func (a *Article) IsPublished(now int) bool {
+	if a.PublishAt > 0 {
+		if a.PublishAt > now {
+			return false
+		}
+	} else if a.State != 1 {
+		return false
+	}
+
+	return a.UnpublishAt == 0 || a.UnpublishAt > now
+}
+
+//文章与标签的关联表，多对多关系
+type ArticleTag struct {
+	ArticleID int `gorm:"primary_key;auto_increment:false"`
+	TagID     int `gorm:"primary_key;auto_increment:false"`
 }
 
 func ExistArticleByID(id int) (bool, error) {
@@ -32,19 +61,56 @@ func ExistArticleByID(id int) (bool, error) {
 	return false, nil
 }
 
-func GetArticleTotal(maps interface{}) (int, error) {
+func ExistArticleByTitle(title string) (bool, error) {
+	var article Article
+	err := db.Select("id").Where("title = ? AND deleted_on = ?", title, 0).First(&article).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	return article.ID > 0, nil
+}
+
+//按标签筛选文章，matchAll为true时要求文章同时包含所有标签，否则包含其中任意一个即可
+func WithTagIDs(tagIDs []int, matchAll bool) func(*gorm.DB) *gorm.DB {
+	tagIDs = uniqueIDs(tagIDs)
+	return func(scope *gorm.DB) *gorm.DB {
+		if len(tagIDs) == 0 {
+			return scope
+		}
+
+		query := db.Model(&ArticleTag{}).Select("article_id").Where("tag_id IN (?)", tagIDs)
+		if matchAll {
+			query = query.Group("article_id").Having("COUNT(DISTINCT tag_id) = ?", len(tagIDs))
+		}
+
+		//SubQuery 自带括号，写成 IN (?) 会变成只取第一行的标量子查询
+		return scope.Where("id IN ?", query.SubQuery())
+	}
+}
+
+//只查询在now时刻对读者可见的文章，定时任务还没来得及修改State时也按设置的时间判断
+func Published(now int) func(*gorm.DB) *gorm.DB {
+	return func(scope *gorm.DB) *gorm.DB {
+		return scope.
+			Where("(publish_at = ? AND state = ?) OR (publish_at > ? AND publish_at <= ?)", 0, 1, 0, now).
+			Where("unpublish_at = ? OR unpublish_at > ?", 0, now)
+	}
+}
+
+func GetArticleTotal(maps interface{}, scopes ...func(*gorm.DB) *gorm.DB) (int, error) {
 	var count int
-	if err := db.Model(&Article{}).Where(maps).Count(&count).Error; err != nil {
+	if err := db.Model(&Article{}).Scopes(scopes...).Where(maps).Count(&count).Error; err != nil {
 		return 0, err
 	}
 
 	return count, nil
 }
 
-//Preload就是一个预加载器，它会执行两条SQL，分别是SELECT * FROM blog_articles;和SELECT * FROM blog_tag WHERE id IN (1,2,3,4);，那么在查询出结构后，gorm内部处理对应的映射逻辑，将其填充到Article的Tag中，会特别方便，并且避免了循环查询
-func GetArticles(pageNum, pageSize int, maps interface{}) ([]*Article, error) {
+//Preload就是一个预加载器，多对多关系下它会先查出文章，再通过关联表blog_article_tag一次性查出这些文章的全部标签，gorm内部处理对应的映射逻辑，将其填充到Article的Tags中，避免了循环查询
+func GetArticles(pageNum, pageSize int, maps interface{}, scopes ...func(*gorm.DB) *gorm.DB) ([]*Article, error) {
 	var articles []*Article
-	err := db.Preload("Tag").Where(maps).Offset(pageNum).Limit(pageSize).Find(&articles).Error
+	err := preloadTags(db).Scopes(scopes...).Where(maps).Offset(pageNum).Limit(pageSize).Find(&articles).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
@@ -52,47 +118,115 @@ func GetArticles(pageNum, pageSize int, maps interface{}) ([]*Article, error) {
 	return articles, nil
 }
 
-//Article有一个结构体成员是TagID，就是外键。gorm会通过类名+ID的方式去找到这两个类之间的关联关系
-//Article有一个结构体成员是Tag，就是我们嵌套在Article里的Tag结构体，我们可以通过Related进行关联查询
-func GetArticle(id int) (*Article, error) {
-	var article Article
-	err := db.Where("id = ? and deleted_on = ?", id, 0).First(&article).Error
+//只查询封面和正文，包括回收站中的文章，供查找没有被引用的媒体文件时分批读取
+func GetArticleContents(offset, limit int) ([]*Article, error) {
+	var articles []*Article
+	err := db.Select("id, cover_image_url, content").Order("id").Offset(offset).Limit(limit).Find(&articles).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
-	err = db.Model(&article).Related(&article.Tag).Error
+
+	return articles, nil
+}
+
+//只查询ID和修改时间，不预加载标签，供生成站点地图时分批读取
+func GetArticleLastMods(offset, limit int, maps interface{}, scopes ...func(*gorm.DB) *gorm.DB) ([]*Article, error) {
+	var articles []*Article
+	err := db.Select("id, modified_on").Scopes(scopes...).Where(maps).Order("id").Offset(offset).Limit(limit).Find(&articles).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
 
-	return &article, nil
+	return articles, nil
 }
 
-func EditArticle(id int, data interface{}) error {
-	if err := db.Model(&Article{}).Where("id = ? and deleted_on = ?", id, 0).Updates(data).Error; err != nil {
-		return err
+//按ID查询多篇未删除的文章，不保证顺序
+func GetArticlesByIDs(ids []int) ([]*Article, error) {
+	var articles []*Article
+	if len(ids) == 0 {
+		return articles, nil
 	}
 
-	return nil
+	err := preloadTags(db).Where("id IN (?) AND deleted_on = ?", ids, 0).Find(&articles).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return articles, nil
+}
+
+//Article有一个结构体成员是Tags，通过many2many:article_tag声明了与Tag的多对多关系，关联表中的article_id、tag_id分别指向两张表的主键
+func GetArticle(id int) (*Article, error) {
+	var article Article
+	err := preloadTags(db).Where("id = ? and deleted_on = ?", id, 0).First(&article).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &article, nil
+}
+
+//data中的tag_ids会替换文章原有的全部标签，其余字段直接更新到文章表，修改前的标题、内容等保存为历史版本
+func EditArticle(id int, data map[string]interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := saveArticleRevision(tx, id, data); err != nil {
+			return err
+		}
+
+		fields := make(map[string]interface{})
+		for k, v := range data {
+			if k == "tag_ids" {
+				tagIDs, ok := v.([]int)
+				if !ok {
+					return fmt.Errorf("models: invalid tag_ids %T", v)
+				}
+				if err := saveArticleTags(tx, id, tagIDs); err != nil {
+					return err
+				}
+				continue
+			}
+			fields[k] = v
+		}
+
+		return tx.Model(&Article{}).Where("id = ? and deleted_on = ?", id, 0).Updates(fields).Error
+	})
 }
 
 //v.(I) 是什么？
 //v表示一个接口值，I表示接口类型。这个实际就是Golang中的类型断言，用于判断一个接口值的实际类型是否为某个类型，或一个非接口值的类型是否实现了某个接口类型
-func AddArticle(data map[string]interface{}) error {
+// 返回新文章的ID
+func AddArticle(data map[string]interface{}) (int, error) {
 	article := Article{
-		TagID:         data["tag_id"].(int),
 		Title:         data["title"].(string),
 		Desc:          data["desc"].(string),
 		Content:       data["content"].(string),
 		CreatedBy:     data["created_by"].(string),
 		State:         data["state"].(int),
 		CoverImageUrl: data["cover_image_url"].(string),
+		PublishAt:     data["publish_at"].(int),
+		UnpublishAt:   data["unpublish_at"].(int),
 	}
-	if err := db.Create(&article).Error; err != nil {
-		return err
+	//导入文章时保留原来的发布日期
+	if createdOn, ok := data["created_on"].(int); ok && createdOn > 0 {
+		article.CreatedOn = createdOn
+		article.ModifiedOn = createdOn
 	}
+	tagIDs, ok := data["tag_ids"].([]int)
+	if !ok {
+		return 0, fmt.Errorf("models: invalid tag_ids %T", data["tag_ids"])
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&article).Error; err != nil {
+			return err
+		}
 
-	return nil
+		return saveArticleTags(tx, article.ID, tagIDs)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return article.ID, nil
 }
 
 func DeleteArticle(id int) error {
@@ -103,15 +237,140 @@ func DeleteArticle(id int) error {
 	return nil
 }
 
-func CleanAllArticle() error {
+//发布到达发布时间的文章，返回被修改的文章ID
+func PublishDueArticles(now int) ([]int, error) {
+	return updateDueArticles("publish_at", now, 1)
+}
+
+//下线到达下线时间的文章，返回被修改的文章ID
+func UnpublishDueArticles(now int) ([]int, error) {
+	return updateDueArticles("unpublish_at", now, 0)
+}
+
+//column到期的文章修改为state，同时清零column，避免之后手动修改的State被再次覆盖
+func updateDueArticles(column string, now, state int) ([]int, error) {
+	var ids []int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Article{}).Where("deleted_on = ? AND "+column+" > ? AND "+column+" <= ?", 0, 0, now).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return tx.Model(&Article{}).Where("id IN (?)", ids).Updates(map[string]interface{}{
+			"state": state,
+			column:  0,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+//彻底删除在deletedBefore之前软删除的文章
+func CleanAllArticle(deletedBefore int) error {
 	//硬删除要使用 Unscoped()，这是 GORM 的约定
-	if err := db.Unscoped().Where("deleted_on != ? ", 0).Delete(&Article{}).Error; err != nil {
+	if err := db.Unscoped().Where("deleted_on != ? AND deleted_on < ?", 0, deletedBefore).Delete(&Article{}).Error; err != nil {
+		return err
+	}
+	if err := cleanArticleRevisions(); err != nil {
 		return err
 	}
 
+	return cleanArticleTags()
+}
+
+//回收站中的文章，按删除时间倒序
+func GetDeletedArticles(pageNum, pageSize int) ([]*Article, error) {
+	var articles []*Article
+	err := preloadTags(db).Where("deleted_on != ?", 0).Order("deleted_on DESC").Offset(pageNum).Limit(pageSize).Find(&articles).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return articles, nil
+}
+
+func GetDeletedArticleTotal() (int, error) {
+	var count int
+	if err := db.Model(&Article{}).Where("deleted_on != ?", 0).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func ExistDeletedArticleByID(id int) (bool, error) {
+	var article Article
+	err := db.Select("id").Where("id = ? AND deleted_on != ?", id, 0).First(&article).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	return article.ID > 0, nil
+}
+
+//从回收站恢复文章
+func RestoreArticle(id int) error {
+	return db.Model(&Article{}).Where("id = ? AND deleted_on != ?", id, 0).Update("deleted_on", 0).Error
+}
+
+//彻底删除回收站中的文章
+func PurgeArticle(id int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_on != ?", id, 0).Delete(&Article{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id = ?", id).Delete(&ArticleRevision{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("article_id = ?", id).Delete(&ArticleTag{}).Error
+	})
+}
+
+//只预加载未删除的标签
+func preloadTags(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags", "deleted_on = ?", 0)
+}
+
+//先清空文章原有的标签关联，再写入新的关联
+func saveArticleTags(tx *gorm.DB, articleID int, tagIDs []int) error {
+	if err := tx.Where("article_id = ?", articleID).Delete(&ArticleTag{}).Error; err != nil {
+		return err
+	}
+
+	for _, tagID := range uniqueIDs(tagIDs) {
+		if err := tx.Create(&ArticleTag{ArticleID: articleID, TagID: tagID}).Error; err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+//清理已被硬删除的文章或标签遗留的关联记录
+func cleanArticleTags() error {
+	articles := db.Model(&Article{}).Select("id").SubQuery()
+	tags := db.Model(&Tag{}).Select("id").SubQuery()
+
+	return db.Where("article_id NOT IN ? OR tag_id NOT IN ?", articles, tags).Delete(&ArticleTag{}).Error
+}
+
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
 //func (article *Article) BeforeCreate(scope *gorm.Scope) error {
 //	scope.SetColumn("CreatedOn", time.Now().Unix())
 //
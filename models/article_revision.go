@@ -0,0 +1,115 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// ArticleRevision 文章修改前的快照，CreatedBy、CreatedOn 是该版本内容的修改人和修改时间
+type ArticleRevision struct {
+	ID            int    `gorm:"primary_key" json:"id"`
+	ArticleID     int    `json:"article_id"`
+	Title         string `json:"title"`
+	Desc          string `json:"desc"`
+	Content       string `json:"content,omitempty"`
+	CoverImageUrl string `json:"cover_image_url"`
+	CreatedBy     string `json:"created_by"`
+	CreatedOn     int    `json:"created_on"`
+}
+
+// GetArticleRevisions 列表中不返回正文
+func GetArticleRevisions(articleID, pageNum, pageSize int) ([]ArticleRevision, error) {
+	var revisions []ArticleRevision
+	err := db.Select("id, article_id, title, `desc`, cover_image_url, created_by, created_on").
+		Where("article_id = ?", articleID).Order("id DESC").Offset(pageNum).Limit(pageSize).Find(&revisions).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func GetArticleRevisionTotal(articleID int) (int, error) {
+	var count int
+	if err := db.Model(&ArticleRevision{}).Where("article_id = ?", articleID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetArticleRevision 获取文章的指定版本，不存在时ID为0
+func GetArticleRevision(articleID, id int) (*ArticleRevision, error) {
+	var revision ArticleRevision
+	err := db.Where("id = ? AND article_id = ?", id, articleID).First(&revision).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// saveArticleRevision 修改前保存文章当前的内容，内容没有变化时不保存
+func saveArticleRevision(tx *gorm.DB, id int, data map[string]interface{}) error {
+	var article Article
+	err := tx.Where("id = ? AND deleted_on = ?", id, 0).First(&article).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !revisionChanged(&article, data) {
+		return nil
+	}
+
+	revision := ArticleRevision{
+		ArticleID:     article.ID,
+		Title:         article.Title,
+		Desc:          article.Desc,
+		Content:       article.Content,
+		CoverImageUrl: article.CoverImageUrl,
+		CreatedBy:     article.ModifiedBy,
+		CreatedOn:     article.ModifiedOn,
+	}
+	if revision.CreatedBy == "" {
+		revision.CreatedBy = article.CreatedBy
+	}
+	if revision.CreatedOn == 0 {
+		revision.CreatedOn = article.CreatedOn
+	}
+
+	return tx.Create(&revision).Error
+}
+
+func revisionChanged(article *Article, data map[string]interface{}) bool {
+	current := map[string]string{
+		"title":           article.Title,
+		"desc":            article.Desc,
+		"content":         article.Content,
+		"cover_image_url": article.CoverImageUrl,
+	}
+	for k, v := range current {
+		if value, ok := data[k]; ok && value.(string) != v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetArticleRevisionContents 分批读取所有历史版本的封面和正文，供查找被引用的上传文件
+func GetArticleRevisionContents(offset, limit int) ([]*ArticleRevision, error) {
+	var revisions []*ArticleRevision
+	err := db.Select("id, cover_image_url, content").Order("id").Offset(offset).Limit(limit).Find(&revisions).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// cleanArticleRevisions 删除已被硬删除的文章遗留的历史版本
+func cleanArticleRevisions() error {
+	articles := db.Model(&Article{}).Select("id").SubQuery()
+
+	return db.Where("article_id NOT IN ?", articles).Delete(&ArticleRevision{}).Error
+}
@@ -0,0 +1,34 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// ArticleScore 全文检索命中的文章及相关度
+type ArticleScore struct {
+	ID    int
+	Score float64
+}
+
+// 需要在文章表上建立 FULLTEXT 索引，见 README
+const articleMatch = "MATCH(title, `desc`, content) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+// SearchArticles 使用 MySQL 全文索引搜索文章，按相关度倒序
+func SearchArticles(text string, pageNum, pageSize int, maps interface{}, scopes ...func(*gorm.DB) *gorm.DB) ([]ArticleScore, error) {
+	var scores []ArticleScore
+	err := db.Model(&Article{}).Select("id, "+articleMatch+" AS score", text).
+		Scopes(scopes...).Where(maps).Where(articleMatch, text).
+		Order("score DESC, id DESC").Offset(pageNum).Limit(pageSize).Scan(&scores).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+func SearchArticleTotal(text string, maps interface{}, scopes ...func(*gorm.DB) *gorm.DB) (int, error) {
+	var count int
+	if err := db.Model(&Article{}).Scopes(scopes...).Where(maps).Where(articleMatch, text).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -3,20 +3,140 @@ package models
 import "github.com/jinzhu/gorm"
 
 type Auth struct {
-	ID       int    `gorm:"primary_key" json:"id"`
+	Model
+
 	Username string `json:"username"`
-	Password string `json:"password"`
+	//密码只保存哈希值，且不返回给客户端
+	Password string `json:"-"`
+	Role     string `json:"role"`
+}
+
+func GetAuthByUsername(username string) (*Auth, error) {
+	var auth Auth
+	err := db.Where("username = ? AND deleted_on = ?", username, 0).First(&auth).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &auth, nil
+}
+
+func GetAuth(id int) (*Auth, error) {
+	var auth Auth
+	err := db.Where("id = ? AND deleted_on = ?", id, 0).First(&auth).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &auth, nil
 }
 
-func CheckAuth(username, password string) (bool, error) {
+func GetAuths(pageNum, pageSize int, maps interface{}) ([]Auth, error) {
+	var auths []Auth
+	err := db.Where(maps).Offset(pageNum).Limit(pageSize).Find(&auths).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return auths, nil
+}
+
+func GetAuthTotal(maps interface{}) (int, error) {
+	var count int
+	if err := db.Model(&Auth{}).Where(maps).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func ExistAuthByID(id int) (bool, error) {
 	var auth Auth
-	err := db.Select("id").Where(Auth{Username: username, Password: password}).First(&auth).Error
+	err := db.Select("id").Where("id = ? AND deleted_on = ?", id, 0).First(&auth).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return false, err
 	}
-	if auth.ID > 0 {
-		return true, nil
+
+	return auth.ID > 0, nil
+}
+
+func ExistAuthByUsername(username string) (bool, error) {
+	var auth Auth
+	err := db.Select("id").Where("username = ? AND deleted_on = ?", username, 0).First(&auth).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, err
+	}
+
+	return auth.ID > 0, nil
+}
+
+func AddAuth(data map[string]interface{}) error {
+	auth := Auth{
+		Username: data["username"].(string),
+		Password: data["password"].(string),
+		Role:     data["role"].(string),
+	}
+	if err := db.Create(&auth).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AuthSetup 初始化标记，只有 id 为 1 的一行，初始化第一个用户时锁定该行
+type AuthSetup struct {
+	ID int `gorm:"primary_key"`
+}
+
+// AddFirstAuth 没有任何用户时新建用户，已经存在用户时返回 false。
+// 检查和新建在同一个事务中进行，并发的初始化请求在标记行上排队，不会创建出两个管理员
+func AddFirstAuth(data map[string]interface{}) (bool, error) {
+	added := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var setup AuthSetup
+		if err := tx.Set("gorm:query_option", "FOR UPDATE").FirstOrCreate(&setup, AuthSetup{ID: 1}).Error; err != nil {
+			return err
+		}
+
+		var count int
+		if err := tx.Model(&Auth{}).Where("deleted_on = ?", 0).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		auth := Auth{
+			Username: data["username"].(string),
+			Password: data["password"].(string),
+			Role:     data["role"].(string),
+		}
+		if err := tx.Create(&auth).Error; err != nil {
+			return err
+		}
+		added = true
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return added, nil
+}
+
+func EditAuth(id int, data interface{}) error {
+	if err := db.Model(&Auth{}).Where("id = ? AND deleted_on = ?", id, 0).Updates(data).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func DeleteAuth(id int) error {
+	if err := db.Where("id = ?", id).Delete(&Auth{}).Error; err != nil {
+		return err
 	}
 
-	return false, nil
+	return nil
 }
@@ -0,0 +1,53 @@
+package models
+
+import "github.com/jinzhu/gorm"
+
+// JobRun 定时任务的执行记录
+type JobRun struct {
+	ID        int    `gorm:"primary_key" json:"id"`
+	Name      string `json:"name"`
+	Host      string `json:"host"`
+	Trigger   string `json:"trigger"`
+	StartedOn int    `json:"started_on"`
+	//执行耗时，单位毫秒
+	Duration int    `json:"duration"`
+	Error    string `json:"error"`
+}
+
+func AddJobRun(run *JobRun) error {
+	if err := db.Create(run).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func GetJobRuns(pageNum, pageSize int, maps interface{}) ([]JobRun, error) {
+	var runs []JobRun
+	err := db.Where(maps).Order("id DESC").Offset(pageNum).Limit(pageSize).Find(&runs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func GetJobRunTotal(maps interface{}) (int, error) {
+	var count int
+	if err := db.Model(&JobRun{}).Where(maps).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetLastJobRun 获取任务最近一次执行记录，没有时 ID 为 0
+func GetLastJobRun(name string) (*JobRun, error) {
+	var run JobRun
+	err := db.Where("name = ?", name).Order("id DESC").First(&run).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &run, nil
+}
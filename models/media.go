@@ -0,0 +1,187 @@
+package models
+
+import (
+	"github.com/jinzhu/gorm"
+	"sort"
+	"strings"
+)
+
+// Media 上传的文件，按内容的 SHA-256 去重，相同的内容只保存一份
+type Media struct {
+	ID int `gorm:"primary_key" json:"id"`
+
+	Tags []Tag `json:"tags" gorm:"many2many:media_tag;"`
+
+	Hash string `json:"hash"`
+	Name string `json:"name"`
+	//第一次上传时的文件名
+	Filename string `json:"filename"`
+	MimeType string `json:"mime_type"`
+	Size     int    `json:"size"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	//上传次数，重复上传相同的内容时增加。不是被文章引用的次数，是否被引用由 GetMediaArticleIDs 根据文章内容判断
+	UploadCount int    `json:"upload_count"`
+	CreatedBy   string `json:"created_by"`
+	CreatedOn   int    `json:"created_on"`
+	ModifiedOn  int    `json:"modified_on"`
+
+	//访问地址，不保存到数据库
+	Url string `json:"url" gorm:"-"`
+}
+
+type MediaTag struct {
+	MediaID int `gorm:"primary_key;auto_increment:false"`
+	TagID   int `gorm:"primary_key;auto_increment:false"`
+}
+
+// GetMediaByHash 不存在时 ID 为 0
+func GetMediaByHash(hash string) (*Media, error) {
+	var media Media
+	err := db.Where("hash = ?", hash).First(&media).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+// AddMedia hash 上有唯一索引，并发上传相同的内容时只有一个能成功
+func AddMedia(media *Media) error {
+	if err := db.Create(media).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IncrMediaUploadCount 增加上传次数，同时更新 modified_on
+func IncrMediaUploadCount(id int) error {
+	return db.Model(&Media{}).Where("id = ?", id).Update("upload_count", gorm.Expr("upload_count + ?", 1)).Error
+}
+
+func GetMediaList(pageNum, pageSize int, maps interface{}, scopes ...func(*gorm.DB) *gorm.DB) ([]*Media, error) {
+	var list []*Media
+	err := preloadTags(db).Scopes(scopes...).Where(maps).Order("id DESC").Offset(pageNum).Limit(pageSize).Find(&list).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func GetMediaTotal(maps interface{}, scopes ...func(*gorm.DB) *gorm.DB) (int, error) {
+	var count int
+	if err := db.Model(&Media{}).Scopes(scopes...).Where(maps).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetMedia 不存在时 ID 为 0
+func GetMedia(id int) (*Media, error) {
+	var media Media
+	err := preloadTags(db).Where("id = ?", id).First(&media).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+// GetMediaByHashes 按哈希批量查询，不预加载标签
+func GetMediaByHashes(hashes []string) ([]*Media, error) {
+	var list []*Media
+	if len(hashes) == 0 {
+		return list, nil
+	}
+
+	err := db.Where("hash IN (?)", hashes).Find(&list).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// 按文件名中包含的内容或哈希的开头搜索，q 中的 %、_ 按普通字符匹配
+func WithMediaQuery(q string) func(*gorm.DB) *gorm.DB {
+	return func(scope *gorm.DB) *gorm.DB {
+		if q == "" {
+			return scope
+		}
+		q := escapeLike(q)
+		return scope.Where("filename LIKE ? ESCAPE '!' OR hash LIKE ? ESCAPE '!'", "%"+q+"%", q+"%")
+	}
+}
+
+// escapeLike 转义 LIKE 中的通配符，使用 ! 作为转义符，MySQL 和 SQLite 中写法相同
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// 按标签筛选，包含其中任意一个标签即可
+func WithMediaTagIDs(tagIDs []int) func(*gorm.DB) *gorm.DB {
+	tagIDs = uniqueIDs(tagIDs)
+	return func(scope *gorm.DB) *gorm.DB {
+		if len(tagIDs) == 0 {
+			return scope
+		}
+		media := db.Model(&MediaTag{}).Select("media_id").Where("tag_id IN (?)", tagIDs).SubQuery()
+		return scope.Where("id IN ?", media)
+	}
+}
+
+// EditMediaTags 替换媒体文件的标签
+func EditMediaTags(id int, tagIDs []int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", id).Delete(&MediaTag{}).Error; err != nil {
+			return err
+		}
+
+		for _, tagID := range uniqueIDs(tagIDs) {
+			if err := tx.Create(&MediaTag{MediaID: id, TagID: tagID}).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteMedia 媒体文件没有回收站，记录和标签关联直接删除
+func DeleteMedia(id int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("media_id = ?", id).Delete(&MediaTag{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).Delete(&Media{}).Error
+	})
+}
+
+// 清理已被硬删除的标签遗留的关联记录
+func cleanMediaTags() error {
+	tags := db.Model(&Tag{}).Select("id").SubQuery()
+
+	return db.Where("tag_id NOT IN ?", tags).Delete(&MediaTag{}).Error
+}
+
+// GetMediaArticleIDs 封面或正文中包含 hash 的文章，包括回收站中的文章，
+// 以及历史版本中包含 hash 的文章，恢复历史版本后会重新引用该文件
+func GetMediaArticleIDs(hash string) ([]int, error) {
+	var articleIDs, revisionIDs []int
+	like := "%" + escapeLike(hash) + "%"
+	where := "cover_image_url LIKE ? ESCAPE '!' OR content LIKE ? ESCAPE '!'"
+	if err := db.Model(&Article{}).Where(where, like, like).Pluck("id", &articleIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&ArticleRevision{}).Where(where, like, like).Pluck("article_id", &revisionIDs).Error; err != nil {
+		return nil, err
+	}
+
+	ids := uniqueIDs(append(articleIDs, revisionIDs...))
+	sort.Ints(ids)
+	return ids, nil
+}
@@ -19,9 +19,7 @@ type Model struct {
 }
 
 func Setup() {
-	var err error
-
-	db, err = gorm.Open(setting.DatabaseSetting.Type, fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True&loc=Local",
+	conn, err := gorm.Open(setting.DatabaseSetting.Type, fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True&loc=Local",
 		setting.DatabaseSetting.User,
 		setting.DatabaseSetting.Password,
 		setting.DatabaseSetting.Host,
@@ -31,6 +29,13 @@ func Setup() {
 		log.Fatalf("models.Setup err: %v", err)
 	}
 
+	SetDB(conn)
+}
+
+// SetDB 使用已经打开的连接，并注册表名前缀和时间字段的钩子，测试时用于替换为内存数据库
+func SetDB(conn *gorm.DB) {
+	db = conn
+
 	gorm.DefaultTableNameHandler = func(db *gorm.DB, defaultTableName string) string {
 		return setting.DatabaseSetting.TablePrefix + defaultTableName
 	}
@@ -1,6 +1,12 @@
 package models
 
-import "github.com/jinzhu/gorm"
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+)
+
+//主动回滚事务时返回，不作为错误返回给调用方
+var errRollback = errors.New("rollback")
 
 type Tag struct {
 	Model
@@ -50,6 +56,21 @@ func ExistTagByName(name string) (bool, error) {
 	return false, nil
 }
 
+//按名称查询多个未删除的标签
+func GetTagsByNames(names []string) ([]Tag, error) {
+	var tags []Tag
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	err := db.Where("name IN (?) AND deleted_on = ?", names, 0).Find(&tags).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
 func AddTag(name string, state int, createdBy string) error {
 	tag := Tag{
 		Name:      name,
@@ -75,6 +96,32 @@ func ExistTagByID(id int) (bool, error) {
 	return false, nil
 }
 
+//标签不存在时返回的Tag ID为0
+func GetTag(id int) (*Tag, error) {
+	var tag Tag
+	err := db.Where("id = ? AND deleted_on = ?", id, 0).First(&tag).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+//检查给定的标签是否全部存在
+func ExistTagsByIDs(ids []int) (bool, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	var count int
+	if err := db.Model(&Tag{}).Where("id IN (?) AND deleted_on = ?", ids, 0).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count == len(ids), nil
+}
+
 func DeleteTag(id int) error {
 	if err := db.Where("id = ?", id).Delete(&Tag{}).Error; err != nil {
 		return err
@@ -91,15 +138,122 @@ func EditTag(id int, data interface{}) error {
 	return nil
 }
 
-func CleanAllTag() (bool, error) {
+//彻底删除在deletedBefore之前软删除的标签
+func CleanAllTag(deletedBefore int) (bool, error) {
 	//硬删除要使用 Unscoped()，这是 GORM 的约定
-	if err := db.Unscoped().Where("deleted_on != ? ", 0).Delete(&Tag{}).Error; err != nil {
+	if err := db.Unscoped().Where("deleted_on != ? AND deleted_on < ?", 0, deletedBefore).Delete(&Tag{}).Error; err != nil {
+		return false, err
+	}
+	if err := cleanArticleTags(); err != nil {
+		return false, err
+	}
+	if err := cleanMediaTags(); err != nil {
 		return false, err
 	}
 
 	return true, nil
 }
 
+//回收站中的标签，按删除时间倒序
+func GetDeletedTags(pageNum, pageSize int) ([]Tag, error) {
+	var tags []Tag
+	err := db.Where("deleted_on != ?", 0).Order("deleted_on DESC").Offset(pageNum).Limit(pageSize).Find(&tags).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func GetDeletedTagTotal() (int, error) {
+	var count int
+	if err := db.Model(&Tag{}).Where("deleted_on != ?", 0).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func GetDeletedTag(id int) (*Tag, error) {
+	var tag Tag
+	err := db.Where("id = ? AND deleted_on != ?", id, 0).First(&tag).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+//从回收站恢复标签
+func RestoreTag(id int) error {
+	return db.Model(&Tag{}).Where("id = ? AND deleted_on != ?", id, 0).Update("deleted_on", 0).Error
+}
+
+//彻底删除回收站中的标签
+func PurgeTag(id int) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_on != ?", id, 0).Delete(&Tag{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("tag_id = ?", id).Delete(&ArticleTag{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("tag_id = ?", id).Delete(&MediaTag{}).Error
+	})
+}
+
+//导入标签时对单个标签的修改，Update为true时按名称更新已有标签的状态，否则新建标签，执行失败时记录在Err中
+type TagChange struct {
+	Name   string
+	State  int
+	User   string
+	Update bool
+	Err    error
+}
+
+//批量执行导入标签的修改，返回修改是否已经提交。
+//commit为false时在事务中执行后回滚，用于预览导入结果；atomic为true时任何一个修改失败都会回滚全部修改
+func ApplyTagChanges(changes []*TagChange, atomic, commit bool) (bool, error) {
+	if !atomic && commit {
+		for _, change := range changes {
+			change.Err = applyTagChange(db, change)
+		}
+		return true, nil
+	}
+
+	committed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		failed := false
+		for _, change := range changes {
+			if change.Err = applyTagChange(tx, change); change.Err != nil {
+				failed = true
+			}
+		}
+		if !commit || (atomic && failed) {
+			return errRollback
+		}
+
+		committed = true
+		return nil
+	})
+	if err != nil && err != errRollback {
+		return false, err
+	}
+
+	return committed, nil
+}
+
+func applyTagChange(tx *gorm.DB, change *TagChange) error {
+	if change.Update {
+		return tx.Model(&Tag{}).Where("name = ? AND deleted_on = ?", change.Name, 0).
+			Updates(map[string]interface{}{"state": change.State, "modified_by": change.User}).Error
+	}
+
+	return tx.Create(&Tag{Name: change.Name, State: change.State, CreatedBy: change.User}).Error
+}
+
 //func (tag *Tag) BeforeCreate(scope *gorm.Scope) error {
 //	scope.SetColumn("CreatedOn", time.Now().Unix())
 //
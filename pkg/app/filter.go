@@ -0,0 +1,28 @@
+package app
+
+import (
+	"gin-blog/pkg/util"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"regexp"
+)
+
+var tagMatchRegexp = regexp.MustCompile("^(any|all)$")
+
+// TagFilters 读取文章列表、搜索和导出共用的标签筛选条件：tag_id、tag_ids、tag_match
+func TagFilters(c *gin.Context, valid *validation.Validation) ([]int, bool) {
+	var tagIds []int
+	if arg := c.Query("tag_id"); arg != "" {
+		tagIds = append(tagIds, com.StrTo(arg).MustInt())
+	}
+	tagIds = append(tagIds, util.GetIntList(c, "tag_ids")...)
+	for _, tagId := range tagIds {
+		valid.Min(tagId, 1, "tag_id").Message("标签ID必须大于0")
+	}
+
+	tagMatch := c.DefaultQuery("tag_match", "any")
+	valid.Match(tagMatch, tagMatchRegexp, "tag_match").Message("标签匹配方式只允许any或all")
+
+	return tagIds, tagMatch == "all"
+}
@@ -0,0 +1,252 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 编辑距离超过该值时不再寻找最短编辑序列，直接把中间不同的部分整体替换，避免大段改写时耗费过多内存
+const maxEdits = 2000
+
+type op int
+
+const (
+	opEqual op = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	op   op
+	line string
+}
+
+// Unified 按行比较 a、b，返回 unified 格式的差异，context 为每段差异前后保留的相同行数，内容相同时返回空字符串
+func Unified(aName, bName, a, b string, context int) string {
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	for _, h := range hunks(edits, context) {
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- %s\n+++ %s\n", aName, bName)
+		}
+		sb.WriteString(h)
+	}
+
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func diffLines(a, b []string) []edit {
+	//先去掉相同的前缀和后缀，只比较中间不同的部分
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, edit{opEqual, line})
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if mid, ok := myers(midA, midB); ok {
+		edits = append(edits, mid...)
+	} else {
+		for _, line := range midA {
+			edits = append(edits, edit{opDelete, line})
+		}
+		for _, line := range midB {
+			edits = append(edits, edit{opInsert, line})
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, edit{opEqual, line})
+	}
+
+	return edits
+}
+
+// myers 使用 Myers 算法求最短编辑序列，编辑距离超过 maxEdits 时返回 false
+// http://www.xmailserver.org/diff2.pdf
+func myers(a, b []string) ([]edit, bool) {
+	n, m := len(a), len(b)
+	total := n + m
+	if total == 0 {
+		return nil, true
+	}
+
+	offset := total + 1
+	v := make([]int, 2*total+3)
+	//trace[d] 保存第 d 步结束后 k 在 [-d, d] 范围内能到达的最远 x，用于回溯
+	var trace [][]int
+
+	for d := 0; d <= total && d <= maxEdits; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+				return backtrack(a, b, trace), true
+			}
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+	}
+
+	return nil, false
+}
+
+func backtrack(a, b []string, trace [][]int) []edit {
+	x, y := len(a), len(b)
+	edits := make([]edit, 0, x+y)
+
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{opEqual, a[x]})
+		}
+		if prevK == k+1 {
+			y--
+			edits = append(edits, edit{opInsert, b[y]})
+		} else {
+			x--
+			edits = append(edits, edit{opDelete, a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{opEqual, a[x]})
+	}
+
+	//回溯得到的是倒序的编辑序列
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+
+	return edits
+}
+
+// hunks 将编辑序列按 context 切分为多段，相距不超过 2*context 行的差异合并为一段
+func hunks(edits []edit, context int) []string {
+	var result []string
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == opEqual {
+			i++
+			continue
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != opEqual {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		stop := end + context + 1
+		if stop > len(edits) {
+			stop = len(edits)
+		}
+
+		result = append(result, formatHunk(edits, start, stop))
+		i = stop
+	}
+
+	return result
+}
+
+func formatHunk(edits []edit, start, stop int) string {
+	//hunk 之前两边各有多少行
+	aLine, bLine := 0, 0
+	for _, e := range edits[:start] {
+		if e.op != opInsert {
+			aLine++
+		}
+		if e.op != opDelete {
+			bLine++
+		}
+	}
+
+	var (
+		body           strings.Builder
+		aCount, bCount int
+	)
+	for _, e := range edits[start:stop] {
+		switch e.op {
+		case opEqual:
+			body.WriteString(" ")
+			aCount++
+			bCount++
+		case opDelete:
+			body.WriteString("-")
+			aCount++
+		case opInsert:
+			body.WriteString("+")
+			bCount++
+		}
+		body.WriteString(e.line)
+		body.WriteString("\n")
+	}
+
+	return fmt.Sprintf("@@ -%s +%s @@\n%s", hunkRange(aLine, aCount), hunkRange(bLine, bCount), body.String())
+}
+
+// 行号从 1 开始，没有行时按惯例使用前一行的行号
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
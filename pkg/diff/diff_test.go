@@ -0,0 +1,216 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    string
+	}{
+		{"both empty", "", "", 3, ""},
+		{"identical", "a\nb\nc\n", "a\nb\nc\n", 3, ""},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "a\nb\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "to empty",
+			a:    "a\nb\n",
+			b:    "",
+			want: "--- a\n+++ b\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name:    "insert only",
+			a:       "1\n2\n3\n4\n5\n",
+			b:       "1\n2\n3\nnew\n4\n5\n",
+			context: 1,
+			want:    "--- a\n+++ b\n@@ -3,2 +3,3 @@\n 3\n+new\n 4\n",
+		},
+		{
+			name:    "delete only",
+			a:       "1\n2\n3\n4\n5\n",
+			b:       "1\n2\n4\n5\n",
+			context: 1,
+			want:    "--- a\n+++ b\n@@ -2,3 +2,2 @@\n 2\n-3\n 4\n",
+		},
+		{
+			name:    "replace",
+			a:       "1\n2\n3\n",
+			b:       "1\ntwo\n3\n",
+			context: 0,
+			want:    "--- a\n+++ b\n@@ -2 +2 @@\n-2\n+two\n",
+		},
+		{
+			name:    "missing trailing newline",
+			a:       "1\n2",
+			b:       "1\n2\n3",
+			context: 1,
+			want:    "--- a\n+++ b\n@@ -2 +2,2 @@\n 2\n+3\n",
+		},
+		{
+			//两处差异之间只有 2*context 行相同，合并为一段
+			name:    "merged hunks",
+			a:       "1\n2\n3\n4\n5\n6\n",
+			b:       "one\n2\n3\n4\n5\nsix\n",
+			context: 2,
+			want:    "--- a\n+++ b\n@@ -1,6 +1,6 @@\n-1\n+one\n 2\n 3\n 4\n 5\n-6\n+six\n",
+		},
+		{
+			//相同的行多于 2*context 时分为两段
+			name:    "separate hunks",
+			a:       "1\n2\n3\n4\n5\n6\n7\n",
+			b:       "one\n2\n3\n4\n5\n6\nseven\n",
+			context: 2,
+			want:    "--- a\n+++ b\n@@ -1,3 +1,3 @@\n-1\n+one\n 2\n 3\n@@ -5,3 +5,3 @@\n 5\n 6\n-7\n+seven\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a", "b", tt.a, tt.b, tt.context); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@$`)
+
+// apply 将 unified diff 应用到 a 的各行，检查 hunk 中的行号和行数
+func apply(t *testing.T, a []string, patch string) []string {
+	t.Helper()
+
+	lines := splitLines(patch)
+	if len(lines) == 0 {
+		return a
+	}
+	if len(lines) < 2 || lines[0] != "--- a" || lines[1] != "+++ b" {
+		t.Fatalf("invalid header:\n%s", patch)
+	}
+
+	var result []string
+	pos := 0
+	for i := 2; i < len(lines); {
+		m := hunkHeader.FindStringSubmatch(lines[i])
+		if m == nil {
+			t.Fatalf("invalid hunk header %q", lines[i])
+		}
+		aStart, aCount := hunkNumbers(m[1], m[2])
+		bStart, bCount := hunkNumbers(m[3], m[4])
+		//没有行时行号为前一行
+		if aCount > 0 {
+			aStart--
+		}
+		if aStart < pos {
+			t.Fatalf("hunk %q overlaps the previous one", lines[i])
+		}
+		result = append(result, a[pos:aStart]...)
+		pos = aStart
+		if bCount > 0 && len(result) != bStart-1 || bCount == 0 && len(result) != bStart {
+			t.Fatalf("hunk %q starts at line %d of b", lines[i], len(result)+1)
+		}
+
+		for i++; i < len(lines) && !strings.HasPrefix(lines[i], "@@"); i++ {
+			line := lines[i][1:]
+			switch lines[i][0] {
+			case ' ':
+				if a[pos] != line {
+					t.Fatalf("context %q does not match %q", line, a[pos])
+				}
+				result = append(result, line)
+				pos++
+				aCount--
+				bCount--
+			case '-':
+				if a[pos] != line {
+					t.Fatalf("deleted %q does not match %q", line, a[pos])
+				}
+				pos++
+				aCount--
+			case '+':
+				result = append(result, line)
+				bCount--
+			}
+		}
+		if aCount != 0 || bCount != 0 {
+			t.Fatalf("hunk line counts are off by %d, %d", aCount, bCount)
+		}
+	}
+
+	return append(result, a[pos:]...)
+}
+
+func hunkNumbers(start, count string) (int, int) {
+	s, _ := strconv.Atoi(start)
+	if count == "" {
+		return s, 1
+	}
+	c, _ := strconv.Atoi(count)
+
+	return s, c
+}
+
+func TestUnifiedRoundTrip(t *testing.T) {
+	lines := func(format string, n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&sb, format+"\n", i)
+		}
+		return sb.String()
+	}
+
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"empty", "", "a\nb\n"},
+		{"scattered", "a\nb\nc\nd\ne\nf\ng\nh\n", "a\nx\nc\nd\ne\nh\ni\n"},
+		{"reordered", "a\nb\nc\na\nb\nc\n", "c\nb\na\nb\na\nc\n"},
+		{"repeated lines", "x\nx\nx\ny\nx\n", "y\nx\nx\ny\ny\n"},
+		{"long", lines("line %d", 300), strings.Replace(lines("line %d", 300), "line 150\n", "changed\nadded\n", 1)},
+		//编辑距离超过 maxEdits 时整体替换
+		{"too many edits", lines("a%d", maxEdits), lines("b%d", maxEdits)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, context := range []int{0, 1, 3} {
+				got := apply(t, splitLines(tt.a), Unified("a", "b", tt.a, tt.b, context))
+				if strings.Join(got, "\n") != strings.Join(splitLines(tt.b), "\n") {
+					t.Errorf("context %d: applying the diff gives\n%s\nwant\n%s", context, strings.Join(got, "\n"), tt.b)
+				}
+			}
+		})
+	}
+}
+
+// 最短编辑序列中删除和插入的行数应为两边不同的行数
+func TestMyersShortest(t *testing.T) {
+	a := strings.Split("a b c a b b a", " ")
+	b := strings.Split("c b a b a c", " ")
+
+	edits, ok := myers(a, b)
+	if !ok {
+		t.Fatal("myers() = false")
+	}
+	changes := 0
+	for _, e := range edits {
+		if e.op != opEqual {
+			changes++
+		}
+	}
+	//Myers 论文中的例子，最短编辑距离为 5
+	if changes != 5 {
+		t.Errorf("edit distance = %d, want 5", changes)
+	}
+}
@@ -3,4 +3,10 @@ package err
 const (
 	CACHE_ARTICLE = "ARTICLE"
 	CACHE_TAG     = "TAG"
+	CACHE_FEED    = "FEED"
+	CACHE_SITEMAP = "SITEMAP"
+
+	CACHE_AUTH_REFRESH = "AUTH_REFRESH"
+	CACHE_AUTH_REVOKED = "AUTH_REVOKED"
+	CACHE_AUTH_SESSION = "AUTH_SESSION"
 )
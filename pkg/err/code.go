@@ -1,9 +1,10 @@
 package err
 
 const (
-	SUCCESS        = 200
-	ERROR          = 500
-	INVALID_PARAMS = 400
+	SUCCESS           = 200
+	ERROR             = 500
+	INVALID_PARAMS    = 400
+	TOO_MANY_REQUESTS = 429
 
 	ERROR_EXIST_TAG       = 10001
 	ERROR_EXIST_TAG_FAIL  = 10002
@@ -26,12 +27,66 @@ const (
 	ERROR_GET_ARTICLE_FAIL         = 10018
 	ERROR_GEN_ARTICLE_POSTER_FAIL  = 10019
 
+	ERROR_NOT_EXIST_TRASH_ARTICLE = 10020
+	ERROR_RESTORE_ARTICLE_FAIL    = 10021
+	ERROR_PURGE_ARTICLE_FAIL      = 10022
+	ERROR_NOT_EXIST_TRASH_TAG     = 10023
+	ERROR_RESTORE_TAG_FAIL        = 10024
+	ERROR_PURGE_TAG_FAIL          = 10025
+	ERROR_NOT_EXIST_REVISION      = 10026
+	ERROR_GET_REVISIONS_FAIL      = 10027
+	ERROR_COUNT_REVISION_FAIL     = 10028
+	ERROR_GET_REVISION_FAIL       = 10029
+	ERROR_RESTORE_REVISION_FAIL   = 10030
+	ERROR_SEARCH_ARTICLES_FAIL    = 10031
+	ERROR_GEN_FEED_FAIL           = 10032
+	ERROR_GEN_SITEMAP_FAIL        = 10033
+	ERROR_NOT_EXIST_SITEMAP       = 10034
+	ERROR_EXPORT_ARTICLE_FAIL     = 10035
+	ERROR_IMPORT_TAG_FILE         = 10036
+	ERROR_IMPORT_ARTICLE_FILE     = 10037
+	ERROR_IMPORT_ARTICLE_FAIL     = 10038
+	ERROR_NOT_EXIST_EXPORT        = 10039
+	ERROR_GET_EXPORT_FAIL         = 10040
+
 	ERROR_AUTH_CHECK_TOKEN_FAIL    = 20001
 	ERROR_AUTH_CHECK_TOKEN_TIMEOUT = 20002
 	ERROR_AUTH_TOKEN               = 20003
 	ERROR_AUTH                     = 20004
+	ERROR_NOT_EXIST_USER           = 20005
+	ERROR_EXIST_USER               = 20006
+	ERROR_CHECK_EXIST_USER_FAIL    = 20007
+	ERROR_GET_USERS_FAIL           = 20008
+	ERROR_COUNT_USER_FAIL          = 20009
+	ERROR_GET_USER_FAIL            = 20010
+	ERROR_ADD_USER_FAIL            = 20011
+	ERROR_EDIT_USER_FAIL           = 20012
+	ERROR_DELETE_USER_FAIL         = 20013
+	ERROR_CHANGE_PASSWORD_FAIL     = 20014
+	ERROR_WRONG_PASSWORD           = 20015
+	ERROR_USER_ALREADY_SETUP       = 20016
+	ERROR_AUTH_PERMISSION_DENIED   = 20017
+	ERROR_AUTH_REFRESH_TOKEN       = 20018
+	ERROR_AUTH_TOKEN_REVOKED       = 20019
+	ERROR_AUTH_LOGOUT_FAIL         = 20020
 
 	ERROR_UPLOAD_SAVE_IMAGE_FAIL    = 30001
 	ERROR_UPLOAD_CHECK_IMAGE_FAIL   = 30002
 	ERROR_UPLOAD_CHECK_IMAGE_FORMAT = 30003
+	ERROR_UPLOAD_IMAGE_TOO_LARGE    = 30004
+	ERROR_UPLOAD_IMAGE_CONTENT      = 30005
+	ERROR_UPLOAD_IMAGE_DIMENSIONS   = 30006
+	ERROR_NOT_EXIST_MEDIA           = 30007
+	ERROR_GET_MEDIA_FAIL            = 30008
+	ERROR_COUNT_MEDIA_FAIL          = 30009
+	ERROR_EDIT_MEDIA_FAIL           = 30010
+	ERROR_DELETE_MEDIA_FAIL         = 30011
+	ERROR_MEDIA_IN_USE              = 30012
+	ERROR_GET_MEDIA_ORPHANS_FAIL    = 30013
+
+	ERROR_NOT_EXIST_JOB     = 40001
+	ERROR_JOB_RUNNING       = 40002
+	ERROR_RUN_JOB_FAIL      = 40003
+	ERROR_GET_JOBS_FAIL     = 40004
+	ERROR_GET_JOB_RUNS_FAIL = 40005
 )
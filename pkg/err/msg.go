@@ -4,6 +4,7 @@ var MsgFlags = map[int]string{
 	SUCCESS:                         "ok",
 	ERROR:                           "fail",
 	INVALID_PARAMS:                  "请求参数错误",
+	TOO_MANY_REQUESTS:               "请求过于频繁，请稍后再试",
 	ERROR_EXIST_TAG:                 "已存在该标签名称",
 	ERROR_EXIST_TAG_FAIL:            "获取已存在标签失败",
 	ERROR_NOT_EXIST_TAG:             "该标签不存在",
@@ -23,13 +24,65 @@ var MsgFlags = map[int]string{
 	ERROR_GET_ARTICLES_FAIL:         "获取多个文章失败",
 	ERROR_GET_ARTICLE_FAIL:          "获取单个文章失败",
 	ERROR_GEN_ARTICLE_POSTER_FAIL:   "生成文章海报失败",
+	ERROR_NOT_EXIST_TRASH_ARTICLE:   "回收站中不存在该文章",
+	ERROR_RESTORE_ARTICLE_FAIL:      "恢复文章失败",
+	ERROR_PURGE_ARTICLE_FAIL:        "彻底删除文章失败",
+	ERROR_NOT_EXIST_TRASH_TAG:       "回收站中不存在该标签",
+	ERROR_RESTORE_TAG_FAIL:          "恢复标签失败",
+	ERROR_PURGE_TAG_FAIL:            "彻底删除标签失败",
+	ERROR_NOT_EXIST_REVISION:        "该历史版本不存在",
+	ERROR_GET_REVISIONS_FAIL:        "获取历史版本列表失败",
+	ERROR_COUNT_REVISION_FAIL:       "统计历史版本失败",
+	ERROR_GET_REVISION_FAIL:         "获取历史版本失败",
+	ERROR_RESTORE_REVISION_FAIL:     "恢复历史版本失败",
+	ERROR_SEARCH_ARTICLES_FAIL:      "搜索文章失败",
+	ERROR_GEN_FEED_FAIL:             "生成订阅失败",
+	ERROR_GEN_SITEMAP_FAIL:          "生成站点地图失败",
+	ERROR_NOT_EXIST_SITEMAP:         "该站点地图不存在",
+	ERROR_EXPORT_ARTICLE_FAIL:       "导出文章失败",
+	ERROR_IMPORT_TAG_FILE:           "导入文件格式不正确，需要包含名称列的xlsx或csv文件",
+	ERROR_IMPORT_ARTICLE_FILE:       "导入文件格式不正确，需要不超过大小限制的Markdown文件zip压缩包",
+	ERROR_IMPORT_ARTICLE_FAIL:       "导入文章失败",
+	ERROR_NOT_EXIST_EXPORT:          "导出文件不存在",
+	ERROR_GET_EXPORT_FAIL:           "获取导出文件失败",
 	ERROR_AUTH_CHECK_TOKEN_FAIL:     "Token鉴权失败",
 	ERROR_AUTH_CHECK_TOKEN_TIMEOUT:  "Token已超时",
 	ERROR_AUTH_TOKEN:                "Token生成失败",
 	ERROR_AUTH:                      "Token错误",
+	ERROR_NOT_EXIST_USER:            "该用户不存在",
+	ERROR_EXIST_USER:                "已存在该用户名",
+	ERROR_CHECK_EXIST_USER_FAIL:     "检查用户是否存在失败",
+	ERROR_GET_USERS_FAIL:            "获取用户列表失败",
+	ERROR_COUNT_USER_FAIL:           "统计用户失败",
+	ERROR_GET_USER_FAIL:             "获取用户失败",
+	ERROR_ADD_USER_FAIL:             "新增用户失败",
+	ERROR_EDIT_USER_FAIL:            "修改用户失败",
+	ERROR_DELETE_USER_FAIL:          "删除用户失败",
+	ERROR_CHANGE_PASSWORD_FAIL:      "修改密码失败",
+	ERROR_WRONG_PASSWORD:            "原密码错误",
+	ERROR_USER_ALREADY_SETUP:        "已存在用户，无法重复初始化",
+	ERROR_AUTH_PERMISSION_DENIED:    "没有操作权限",
+	ERROR_AUTH_REFRESH_TOKEN:        "Refresh Token无效或已过期",
+	ERROR_AUTH_TOKEN_REVOKED:        "Token已失效",
+	ERROR_AUTH_LOGOUT_FAIL:          "退出登录失败",
 	ERROR_UPLOAD_SAVE_IMAGE_FAIL:    "保存图片失败",
 	ERROR_UPLOAD_CHECK_IMAGE_FAIL:   "检查图片失败",
 	ERROR_UPLOAD_CHECK_IMAGE_FORMAT: "校验图片错误，图片格式或大小有问题",
+	ERROR_UPLOAD_IMAGE_TOO_LARGE:    "图片大小超过限制",
+	ERROR_UPLOAD_IMAGE_CONTENT:      "图片内容与格式不符或图片已损坏",
+	ERROR_UPLOAD_IMAGE_DIMENSIONS:   "图片尺寸超过限制",
+	ERROR_NOT_EXIST_MEDIA:           "该媒体文件不存在",
+	ERROR_GET_MEDIA_FAIL:            "获取媒体文件失败",
+	ERROR_COUNT_MEDIA_FAIL:          "统计媒体文件失败",
+	ERROR_EDIT_MEDIA_FAIL:           "修改媒体文件失败",
+	ERROR_DELETE_MEDIA_FAIL:         "删除媒体文件失败",
+	ERROR_MEDIA_IN_USE:              "媒体文件正在被文章使用",
+	ERROR_GET_MEDIA_ORPHANS_FAIL:    "获取未被引用的媒体文件失败",
+	ERROR_NOT_EXIST_JOB:             "该任务不存在",
+	ERROR_JOB_RUNNING:               "任务正在运行",
+	ERROR_RUN_JOB_FAIL:              "执行任务失败",
+	ERROR_GET_JOBS_FAIL:             "获取任务列表失败",
+	ERROR_GET_JOB_RUNS_FAIL:         "获取任务执行记录失败",
 }
 
 func GetMsg(code int) string {
@@ -1,15 +1,34 @@
 package export
 
-import "gin-blog/pkg/setting"
+import (
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"regexp"
+)
 
+// 导出文件名的格式，与 Table.Save 生成的文件名一致：前缀-时间戳-随机数.格式
+var fileNameRegexp = regexp.MustCompile(`^([a-z]+)-[0-9]+-[0-9a-f]{32}\.(xlsx|csv|json)$`)
+
+// GetExcelFullUrl 导出的文件只能通过需要登录的下载接口获取，不直接提供存储中的地址
 func GetExcelFullUrl(name string) string {
-	return setting.AppSetting.PrefixUrl + "/" + GetExcelPath() + name
+	return util.AbsoluteURL("/api/v1/exports/" + name)
 }
 
 func GetExcelPath() string {
 	return setting.AppSetting.ExportSavePath
 }
 
-func GetExcelFullPath() string {
-	return setting.AppSetting.RuntimeRootPath + GetExcelPath()
+// ParseName 检查导出文件名，返回文件名前缀（如 articles、tags）和导出格式
+func ParseName(name string) (string, string, bool) {
+	match := fileNameRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return "", "", false
+	}
+
+	return match[1], match[2], true
+}
+
+// ContentType 导出格式对应的文件类型
+func ContentType(format string) string {
+	return contentTypes[format]
 }
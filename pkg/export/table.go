@@ -0,0 +1,175 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gin-blog/pkg/storage"
+	"gin-blog/pkg/util"
+	"github.com/tealeg/xlsx"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 支持的导出格式，同时也是导出文件的扩展名
+const (
+	FormatXlsx = "xlsx"
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// 各导出格式的文件类型
+var contentTypes = map[string]string{
+	FormatXlsx: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatJSON: "application/json",
+}
+
+// 导出文件中时间的格式
+const timeLayout = "2006-01-02 15:04:05"
+
+// Column 导出的一列，Title 用于 xlsx、CSV 的表头，Key 用于 JSON 的字段名
+type Column struct {
+	Key   string
+	Title string
+}
+
+// Table 导出的表格，Rows 中每一行的值与 Columns 一一对应
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]interface{}
+}
+
+// ValidFormat 检查导出格式是否支持
+func ValidFormat(format string) bool {
+	return format == FormatXlsx || format == FormatCSV || format == FormatJSON
+}
+
+// FormatTime 将 Unix 时间戳转换为便于阅读的时间，0 表示未设置，返回空字符串
+func FormatTime(unix int) string {
+	if unix == 0 {
+		return ""
+	}
+
+	return time.Unix(int64(unix), 0).Format(timeLayout)
+}
+
+// Save 将表格按 format 保存到 GetExcelPath() 下，返回文件名，prefix 为文件名前缀。
+// 导出目录可以公开访问，文件名中带有随机数，防止被猜到，同一秒内的多次导出也不会互相覆盖
+func (t *Table) Save(prefix, format string) (string, error) {
+	token, err := util.RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	filename := prefix + "-" + strconv.Itoa(int(time.Now().Unix())) + "-" + token + "." + format
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatXlsx:
+		err = t.writeXlsx(&buf)
+	case FormatCSV:
+		err = t.writeCSV(&buf)
+	case FormatJSON:
+		err = t.writeJSON(&buf)
+	default:
+		err = fmt.Errorf("unsupported export format: %s", format)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := storage.Default.Put(GetExcelPath()+filename, &buf, contentTypes[format]); err != nil {
+		return "", err
+	}
+
+	return filename, nil
+}
+
+func (t *Table) writeXlsx(w io.Writer) error {
+	file := xlsx.NewFile()
+	sheet, err := file.AddSheet(t.Name)
+	if err != nil {
+		return err
+	}
+
+	row := sheet.AddRow()
+	for _, column := range t.Columns {
+		row.AddCell().SetString(column.Title)
+	}
+	for _, values := range t.Rows {
+		row = sheet.AddRow()
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				row.AddCell().SetString(escapeFormula(s))
+			} else {
+				row.AddCell().SetValue(value)
+			}
+		}
+	}
+
+	return file.Write(w)
+}
+
+func (t *Table) writeCSV(w io.Writer) error {
+	//写入 UTF-8 BOM，否则 Excel 打开时中文会乱码
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	titles := make([]string, 0, len(t.Columns))
+	for _, column := range t.Columns {
+		titles = append(titles, column.Title)
+	}
+	if err := cw.Write(titles); err != nil {
+		return err
+	}
+	for _, values := range t.Rows {
+		record := make([]string, 0, len(values))
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				record = append(record, escapeFormula(s))
+			} else {
+				record = append(record, fmt.Sprint(value))
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// escapeFormula 在以 =、+、-、@ 开头的文本前加上单引号，防止 Excel 等软件打开时当作公式执行
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
+
+func (t *Table) writeJSON(w io.Writer) error {
+	items := make([]map[string]interface{}, 0, len(t.Rows))
+	for _, values := range t.Rows {
+		item := make(map[string]interface{}, len(t.Columns))
+		for i, column := range t.Columns {
+			item[column.Key] = values[i]
+		}
+		items = append(items, item)
+	}
+
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/tealeg/xlsx"
+	"strings"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"标题", "标题"},
+		{"=1+1", "'=1+1"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		if got := escapeFormula(tt.in); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func testTable() *Table {
+	return &Table{
+		Name:    "test",
+		Columns: []Column{{Key: "title", Title: "标题"}, {Key: "id", Title: "ID"}},
+		Rows:    [][]interface{}{{`=HYPERLINK("http://example.com")`, -1}},
+	}
+}
+
+func TestWriteCSVEscapesFormula(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testTable().writeCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\xEF\xBB\xBF"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//文本被转义，数字保持不变
+	if got := records[1]; got[0] != `'=HYPERLINK("http://example.com")` || got[1] != "-1" {
+		t.Errorf("writeCSV() row = %q", got)
+	}
+}
+
+func TestWriteXlsxEscapesFormula(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testTable().writeXlsx(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := xlsx.OpenBinary(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := file.Sheets[0].Rows[1].Cells
+	if got := cells[0].String(); got != `'=HYPERLINK("http://example.com")` {
+		t.Errorf("writeXlsx() cell = %q", got)
+	}
+	if cells[0].Formula() != "" {
+		t.Errorf("writeXlsx() formula = %q, want none", cells[0].Formula())
+	}
+	if got := cells[1].String(); got != "-1" {
+		t.Errorf("writeXlsx() number cell = %q", got)
+	}
+}
@@ -0,0 +1,260 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// Feed 与具体格式无关的订阅内容，链接都应为绝对地址
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Updated     time.Time
+	Items       []*Item
+
+	//RSS、Atom、JSON Feed 各自的订阅地址，写入 self 链接
+	RSSLink  string
+	AtomLink string
+	JSONLink string
+}
+
+type Item struct {
+	ID          string
+	Title       string
+	Link        string
+	Summary     string
+	ContentHTML string
+	Image       string
+	Tags        []string
+	Published   time.Time
+	Updated     time.Time
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Content string     `xml:"xmlns:content,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	Description string        `xml:"description"`
+	Content     *cdata        `xml:"content:encoded,omitempty"`
+	Categories  []string      `xml:"category"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// RSS 生成 RSS 2.0
+func RSS(f *Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		Self:        atomLink{Href: f.RSSLink, Rel: "self", Type: "application/rss+xml"},
+	}
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+
+	for _, item := range f.Items {
+		ri := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: item.ID == item.Link, Value: item.ID},
+			Description: item.Summary,
+			Categories:  item.Tags,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		}
+		if item.ContentHTML != "" {
+			ri.Content = &cdata{Value: item.ContentHTML}
+		}
+		if item.Image != "" {
+			//RSS 要求 length，图片大小未知时按惯例填 0
+			ri.Enclosure = &rssEnclosure{URL: item.Image, Type: imageType(item.Image)}
+		}
+		channel.Items = append(channel.Items, ri)
+	}
+
+	return marshalXML(rss{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		Channel: channel,
+	})
+}
+
+type atom struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Links      []atomLink     `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    *atomContent   `xml:"content"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// Atom 生成 Atom 1.0
+func Atom(f *Feed) ([]byte, error) {
+	feed := atom{
+		ID:    f.Link,
+		Title: f.Title,
+		Links: []atomLink{
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+			{Href: f.AtomLink, Rel: "self", Type: "application/atom+xml"},
+		},
+		Updated: f.Updated.Format(time.RFC3339),
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.ID,
+			Title:     item.Title,
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Updated.Format(time.RFC3339),
+			Summary:   item.Summary,
+		}
+		if item.ContentHTML != "" {
+			entry.Content = &atomContent{Type: "html", Value: item.ContentHTML}
+		}
+		if item.Image != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.Image, Rel: "enclosure", Type: imageType(item.Image)})
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshalXML(feed)
+}
+
+// https://www.jsonfeed.org/version/1.1/
+type jsonFeed struct {
+	Version     string      `json:"version"`
+	Title       string      `json:"title"`
+	HomePageURL string      `json:"home_page_url"`
+	FeedURL     string      `json:"feed_url"`
+	Description string      `json:"description,omitempty"`
+	Items       []*jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentHTML   string   `json:"content_html"`
+	Summary       string   `json:"summary,omitempty"`
+	Image         string   `json:"image,omitempty"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// JSON 生成 JSON Feed 1.1
+func JSON(f *Feed) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.JSONLink,
+		Description: f.Description,
+		Items:       make([]*jsonItem, 0, len(f.Items)),
+	}
+
+	for _, item := range f.Items {
+		feed.Items = append(feed.Items, &jsonItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   item.ContentHTML,
+			Summary:       item.Summary,
+			Image:         item.Image,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  item.Updated.Format(time.RFC3339),
+			Tags:          item.Tags,
+		})
+	}
+
+	return json.Marshal(feed)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// 按扩展名推断图片类型，无法推断时使用 image/jpeg
+func imageType(url string) string {
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	if t := mime.TypeByExtension(strings.ToLower(path.Ext(url))); strings.HasPrefix(t, "image/") {
+		return t
+	}
+
+	return "image/jpeg"
+}
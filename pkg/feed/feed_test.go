@@ -0,0 +1,112 @@
+package feed
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// golden 与 testdata 中的文件比较，-update 时改为写入该文件
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	file := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(file, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match the golden file:\n%s\nwant\n%s", name, got, want)
+	}
+}
+
+func testFeed() *Feed {
+	cst := time.FixedZone("CST", 8*3600)
+
+	return &Feed{
+		Title:       "gin-blog - Go & <Web>",
+		Link:        "http://localhost:8080/api/public/articles?tag_id=1",
+		Description: "使用gin开发的个人博客",
+		Updated:     time.Date(2023, 3, 4, 5, 6, 7, 0, cst),
+		RSSLink:     "http://localhost:8080/tags/1/feed.xml",
+		AtomLink:    "http://localhost:8080/tags/1/atom.xml",
+		JSONLink:    "http://localhost:8080/tags/1/feed.json",
+		Items: []*Item{
+			{
+				ID:      "http://localhost:8080/api/public/articles/2",
+				Title:   "第二篇 <文章> & \"引号\"",
+				Link:    "http://localhost:8080/api/public/articles/2",
+				Summary: "简介",
+				//CDATA 中的 ]]> 需要拆开
+				ContentHTML: "<p>a ]]> b &amp; <code>x</code></p>\n",
+				Image:       "http://localhost:8080/upload/images/ab/cd/a.PNG?v=1",
+				Tags:        []string{"Go", "Web"},
+				Published:   time.Date(2023, 3, 2, 1, 0, 0, 0, cst),
+				Updated:     time.Date(2023, 3, 4, 5, 6, 7, 0, cst),
+			},
+			{
+				ID:        "http://localhost:8080/api/public/articles/1",
+				Title:     "第一篇",
+				Link:      "http://localhost:8080/api/public/articles/1",
+				Image:     "http://localhost:8080/upload/images/cover",
+				Published: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+				Updated:   time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(*Feed) ([]byte, error)
+	}{
+		{"feed.xml", RSS},
+		{"atom.xml", Atom},
+		{"feed.json", JSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.build(testFeed())
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.name, data)
+		})
+	}
+}
+
+// 没有文章时 JSON Feed 的 items 为空数组
+func TestEmptyFeeds(t *testing.T) {
+	f := &Feed{Title: "empty", Link: "http://localhost:8080/", JSONLink: "http://localhost:8080/feed.json"}
+
+	data, err := JSON(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":"https://jsonfeed.org/version/1.1","title":"empty","home_page_url":"http://localhost:8080/","feed_url":"http://localhost:8080/feed.json","items":[]}`
+	if string(data) != want {
+		t.Errorf("JSON() = %s, want %s", data, want)
+	}
+
+	data, err = RSS(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("lastBuildDate")) || bytes.Contains(data, []byte("<item>")) {
+		t.Errorf("RSS() = %s, want no lastBuildDate and items", data)
+	}
+}
@@ -0,0 +1,65 @@
+package gcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gin-blog/pkg/gredis"
+	"gin-blog/pkg/setting"
+	"math/rand"
+	"time"
+)
+
+var ErrNotFound = errors.New("gcache: key not found")
+
+// Cache 缓存后端，ttl 小于等于 0 表示不过期，Get 在 key 不存在时返回 ErrNotFound
+type Cache interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+	DeleteByPrefix(prefix string) error
+}
+
+// New 根据配置 [cache] Type 创建缓存后端
+func New() (Cache, error) {
+	switch setting.CacheSetting.Type {
+	case "", "redis":
+		return NewRedis(gredis.RedisConn), nil
+	case "memory":
+		return NewMemory(setting.CacheSetting.MemorySize), nil
+	case "none":
+		return NewNone(), nil
+	}
+
+	return nil, fmt.Errorf("gcache: unknown cache type %q", setting.CacheSetting.Type)
+}
+
+// GetObject 读取缓存并反序列化到 v
+func GetObject(c Cache, key string, v interface{}) error {
+	data, err := c.Get(key)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// SetObject 将 v 序列化为 JSON 后写入缓存
+func SetObject(c Cache, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.Set(key, data, ttl)
+}
+
+// Jitter 在 ttl 的基础上随机浮动 ±10%，避免同一批写入的缓存在同一时刻集中过期
+func Jitter(ttl time.Duration) time.Duration {
+	delta := int64(ttl) / 10
+	if delta <= 0 {
+		return ttl
+	}
+
+	return ttl + time.Duration(rand.Int63n(2*delta+1)-delta)
+}
@@ -0,0 +1,113 @@
+package gcache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory 进程内的 LRU 缓存，超过容量时淘汰最久未使用的 key，多个实例之间不共享
+type Memory struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+func NewMemory(capacity int) *Memory {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+
+	return &Memory{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (m *Memory) Get(key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	entry := el.Value.(*memoryEntry)
+	if entry.expired(time.Now()) {
+		m.remove(el)
+		return nil, ErrNotFound
+	}
+
+	m.ll.MoveToFront(el)
+	return entry.value, nil
+}
+
+func (m *Memory) Set(key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl)
+	}
+
+	//复制一份，避免调用方修改切片影响缓存内容
+	value = append([]byte(nil), value...)
+	if el, ok := m.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expireAt = expireAt
+		m.ll.MoveToFront(el)
+		return nil
+	}
+
+	m.items[key] = m.ll.PushFront(&memoryEntry{key: key, value: value, expireAt: expireAt})
+	for m.ll.Len() > m.capacity {
+		m.remove(m.ll.Back())
+	}
+
+	return nil
+}
+
+func (m *Memory) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+
+	return nil
+}
+
+func (m *Memory) DeleteByPrefix(prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, el := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			m.remove(el)
+		}
+	}
+
+	return nil
+}
+
+func (m *Memory) remove(el *list.Element) {
+	m.ll.Remove(el)
+	delete(m.items, el.Value.(*memoryEntry).key)
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && now.After(e.expireAt)
+}
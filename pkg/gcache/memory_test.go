@@ -0,0 +1,130 @@
+package gcache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryGetMissing(t *testing.T) {
+	m := NewMemory(10)
+	if _, err := m.Get("missing"); err != ErrNotFound {
+		t.Fatalf("Get() err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	m := NewMemory(2)
+	m.Set("a", []byte("1"), 0)
+	m.Set("b", []byte("2"), 0)
+	//读取 a 后 b 成为最久未使用的 key
+	if _, err := m.Get("a"); err != nil {
+		t.Fatal(err)
+	}
+	m.Set("c", []byte("3"), 0)
+
+	tests := []struct {
+		key  string
+		want error
+	}{
+		{"a", nil},
+		{"b", ErrNotFound},
+		{"c", nil},
+	}
+	for _, tt := range tests {
+		if _, err := m.Get(tt.key); err != tt.want {
+			t.Errorf("Get(%q) err = %v, want %v", tt.key, err, tt.want)
+		}
+	}
+}
+
+func TestMemoryOverwriteDoesNotEvict(t *testing.T) {
+	m := NewMemory(2)
+	m.Set("a", []byte("1"), 0)
+	m.Set("b", []byte("2"), 0)
+	m.Set("a", []byte("3"), 0)
+
+	if v, err := m.Get("a"); err != nil || string(v) != "3" {
+		t.Errorf("Get(a) = %q, %v, want 3", v, err)
+	}
+	if _, err := m.Get("b"); err != nil {
+		t.Errorf("Get(b) err = %v, want nil", err)
+	}
+}
+
+func TestMemoryExpires(t *testing.T) {
+	m := NewMemory(10)
+	m.Set("short", []byte("1"), 10*time.Millisecond)
+	m.Set("forever", []byte("2"), 0)
+
+	if _, err := m.Get("short"); err != nil {
+		t.Fatalf("Get(short) before expiry err = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := m.Get("short"); err != ErrNotFound {
+		t.Errorf("Get(short) after expiry err = %v, want ErrNotFound", err)
+	}
+	if _, err := m.Get("forever"); err != nil {
+		t.Errorf("Get(forever) err = %v, want nil", err)
+	}
+}
+
+func TestMemoryDeleteByPrefix(t *testing.T) {
+	m := NewMemory(10)
+	for _, key := range []string{"ARTICLE_1", "ARTICLE_LIST_1", "TAG_1"} {
+		m.Set(key, []byte("1"), 0)
+	}
+
+	if err := m.DeleteByPrefix("ARTICLE_"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]error{"ARTICLE_1": ErrNotFound, "ARTICLE_LIST_1": ErrNotFound, "TAG_1": nil} {
+		if _, err := m.Get(key); err != want {
+			t.Errorf("Get(%q) err = %v, want %v", key, err, want)
+		}
+	}
+}
+
+func TestMemoryCopiesValue(t *testing.T) {
+	m := NewMemory(10)
+	value := []byte("abc")
+	m.Set("a", value, 0)
+	value[0] = 'x'
+
+	if v, _ := m.Get("a"); string(v) != "abc" {
+		t.Errorf("Get(a) = %q, want abc", v)
+	}
+}
+
+func TestNone(t *testing.T) {
+	n := NewNone()
+	if err := n.Set("a", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := n.Get("a"); err != ErrNotFound {
+		t.Errorf("Get() err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestObjectRoundTrip(t *testing.T) {
+	m := NewMemory(10)
+	if err := SetObject(m, "a", []int{1, 2}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int
+	if err := GetObject(m, "a", &got); err != nil || len(got) != 2 || got[1] != 2 {
+		t.Errorf("GetObject() = %v, %v", got, err)
+	}
+	if err := GetObject(m, "missing", &got); err != ErrNotFound {
+		t.Errorf("GetObject(missing) err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if d := Jitter(time.Hour); d < 54*time.Minute || d > 66*time.Minute {
+			t.Fatalf("Jitter(1h) = %v, want within ±10%%", d)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package gcache
+
+import "time"
+
+// None 不缓存任何数据，所有读取都穿透到数据库
+type None struct{}
+
+func NewNone() None {
+	return None{}
+}
+
+func (None) Get(key string) ([]byte, error) {
+	return nil, ErrNotFound
+}
+
+func (None) Set(key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (None) Delete(key string) error {
+	return nil
+}
+
+func (None) DeleteByPrefix(prefix string) error {
+	return nil
+}
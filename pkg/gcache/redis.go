@@ -0,0 +1,80 @@
+package gcache
+
+import (
+	"github.com/gomodule/redigo/redis"
+	"strings"
+	"time"
+)
+
+type Redis struct {
+	pool *redis.Pool
+}
+
+func NewRedis(pool *redis.Pool) *Redis {
+	return &Redis{pool: pool}
+}
+
+func (r *Redis) Get(key string) ([]byte, error) {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	reply, err := redis.Bytes(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		return nil, ErrNotFound
+	}
+
+	return reply, err
+}
+
+func (r *Redis) Set(key string, value []byte, ttl time.Duration) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	var err error
+	if ms := ttl.Milliseconds(); ms > 0 {
+		_, err = conn.Do("SET", key, value, "PX", ms)
+	} else {
+		_, err = conn.Do("SET", key, value)
+	}
+
+	return err
+}
+
+func (r *Redis) Delete(key string) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("DEL", key)
+	return err
+}
+
+// DeleteByPrefix 使用 SCAN 分批查找并删除，避免 KEYS 阻塞 Redis
+func (r *Redis) DeleteByPrefix(prefix string) error {
+	conn := r.pool.Get()
+	defer conn.Close()
+
+	pattern := globEscaper.Replace(prefix) + "*"
+	cursor := 0
+	for {
+		values, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", pattern, "COUNT", 100))
+		if err != nil {
+			return err
+		}
+
+		var keys []string
+		if _, err := redis.Scan(values, &cursor, &keys); err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if _, err := conn.Do("DEL", redis.Args{}.AddFlat(keys)...); err != nil {
+				return err
+			}
+		}
+
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
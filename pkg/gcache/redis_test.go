@@ -0,0 +1,70 @@
+package gcache
+
+import (
+	"github.com/gomodule/redigo/redis"
+	"os"
+	"testing"
+	"time"
+)
+
+// 设置 GCACHE_TEST_REDIS（如 127.0.0.1:6379）时使用真实的 Redis 测试，测试会写入 GCACHE_TEST_ 开头的 key
+func newTestRedis(t *testing.T) *Redis {
+	addr := os.Getenv("GCACHE_TEST_REDIS")
+	if addr == "" {
+		t.Skip("GCACHE_TEST_REDIS not set")
+	}
+
+	pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", addr) }}
+	t.Cleanup(func() { pool.Close() })
+
+	r := NewRedis(pool)
+	if err := r.DeleteByPrefix("GCACHE_TEST_"); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRedis(t *testing.T) {
+	r := newTestRedis(t)
+
+	if _, err := r.Get("GCACHE_TEST_missing"); err != ErrNotFound {
+		t.Fatalf("Get(missing) err = %v, want ErrNotFound", err)
+	}
+
+	r.Set("GCACHE_TEST_short", []byte("1"), 50*time.Millisecond)
+	r.Set("GCACHE_TEST_a*1", []byte("2"), 0)
+	r.Set("GCACHE_TEST_ab", []byte("3"), 0)
+	if v, err := r.Get("GCACHE_TEST_short"); err != nil || string(v) != "1" {
+		t.Fatalf("Get(short) = %q, %v", v, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, err := r.Get("GCACHE_TEST_short"); err != ErrNotFound {
+		t.Errorf("Get(short) after expiry err = %v, want ErrNotFound", err)
+	}
+
+	//前缀中的 * 按字面匹配，不能删除 GCACHE_TEST_ab
+	if err := r.DeleteByPrefix("GCACHE_TEST_a*"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Get("GCACHE_TEST_a*1"); err != ErrNotFound {
+		t.Errorf("Get(a*1) err = %v, want ErrNotFound", err)
+	}
+	if _, err := r.Get("GCACHE_TEST_ab"); err != nil {
+		t.Errorf("Get(ab) err = %v, want nil", err)
+	}
+}
+
+func TestGlobEscaper(t *testing.T) {
+	tests := []struct {
+		prefix, want string
+	}{
+		{"ARTICLE_", "ARTICLE_"},
+		{"a*b?c[d]", `a\*b\?c\[d\]`},
+		{`a\b`, `a\\b`},
+	}
+	for _, tt := range tests {
+		if got := globEscaper.Replace(tt.prefix); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -37,6 +37,7 @@ func Setup() error {
 	return nil
 }
 
+// time为过期秒数，小于等于0时不过期
 func Set(key string, data interface{}, time int) (bool, error) {
 	conn := RedisConn.Get()
 	defer conn.Close()
@@ -46,22 +47,24 @@ func Set(key string, data interface{}, time int) (bool, error) {
 		return false, err
 	}
 
-	reply, err := redis.Bool(conn.Do("SET", key, value))
-	conn.Do("EXPIRE", key, time)
+	//SET 成功时返回状态回复 OK，不能用 redis.Bool 转换；过期时间与值一起设置
+	args := redis.Args{key, value}
+	if time > 0 {
+		args = args.Add("EX", time)
+	}
+	reply, err := redis.String(conn.Do("SET", args...))
+	if err != nil {
+		return false, err
+	}
 
-	return reply, err
+	return reply == "OK", nil
 }
 
-func Exists(key string) bool {
+func Exists(key string) (bool, error) {
 	conn := RedisConn.Get()
 	defer conn.Close()
 
-	exists, err := redis.Bool(conn.Do("EXISTS", key))
-	if err != nil {
-		return false
-	}
-
-	return exists
+	return redis.Bool(conn.Do("EXISTS", key))
 }
 
 func Get(key string) ([]byte, error) {
@@ -83,6 +86,38 @@ func Delete(key string) (bool, error) {
 	return redis.Bool(conn.Do("DEL", key))
 }
 
+// SAdd 向集合中添加成员，time为集合的过期秒数，小于等于0时不过期
+func SAdd(key, member string, time int) error {
+	conn := RedisConn.Get()
+	defer conn.Close()
+
+	if _, err := conn.Do("SADD", key, member); err != nil {
+		return err
+	}
+	if time > 0 {
+		if _, err := conn.Do("EXPIRE", key, time); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func SRem(key, member string) error {
+	conn := RedisConn.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("SREM", key, member)
+	return err
+}
+
+func SMembers(key string) ([]string, error) {
+	conn := RedisConn.Get()
+	defer conn.Close()
+
+	return redis.Strings(conn.Do("SMEMBERS", key))
+}
+
 func LikeDeletes(key string) error {
 	conn := RedisConn.Get()
 	defer conn.Close()
@@ -101,3 +136,36 @@ func LikeDeletes(key string) error {
 
 	return nil
 }
+
+// 只有持有锁的token才能释放，避免锁过期后误删其他实例的锁
+var unlockScript = redis.NewScript(1, `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock 尝试获取分布式锁，获取成功时返回true，ttl为锁的过期秒数
+func Lock(key, token string, ttl int) (bool, error) {
+	conn := RedisConn.Get()
+	defer conn.Close()
+
+	_, err := redis.String(conn.Do("SET", key, token, "NX", "EX", ttl))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Unlock 释放token持有的锁
+func Unlock(key, token string) error {
+	conn := RedisConn.Get()
+	defer conn.Close()
+
+	_, err := unlockScript.Do(conn, key, token)
+	return err
+}
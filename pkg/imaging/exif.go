@@ -0,0 +1,162 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	tagOrientation = 0x0112
+	tagGPSInfo     = 0x8825
+)
+
+// 各 TIFF 数据类型每个值占用的字节数，下标为类型编号
+var typeSizes = [...]int{0, 1, 1, 2, 4, 8, 1, 1, 2, 4, 8, 4, 8}
+
+// Orientation 返回 JPEG 中 EXIF 的方向（1-8），没有 EXIF 或无法解析时返回 1
+func Orientation(data []byte) int {
+	t := findTIFF(data)
+	if t == nil {
+		return 1
+	}
+
+	if entry := t.find(t.ifd0(), tagOrientation); entry >= 0 {
+		if o := int(t.order.Uint16(t.data[entry+8:])); o >= 1 && o <= 8 {
+			return o
+		}
+	}
+
+	return 1
+}
+
+// StripGPS 清除 JPEG 中 EXIF 的 GPS 信息，data 会被原地修改。
+// GPS IFD 被改写为空目录，数据长度和其他 EXIF 信息（包括方向）保持不变
+func StripGPS(data []byte) {
+	t := findTIFF(data)
+	if t == nil {
+		return
+	}
+
+	entry := t.find(t.ifd0(), tagGPSInfo)
+	if entry < 0 {
+		return
+	}
+	offset := int(t.order.Uint32(t.data[entry+8:]))
+	if offset <= 0 || offset+2 > len(t.data) {
+		return
+	}
+
+	n := int(t.order.Uint16(t.data[offset:]))
+	end := offset + 2 + 12*n + 4
+	if end > len(t.data) {
+		return
+	}
+	for i := 0; i < n; i++ {
+		e := offset + 2 + 12*i
+		//超过 4 字节的值保存在目录之外，需要一并清除
+		if size := t.valueSize(e); size > 4 {
+			start := int(t.order.Uint32(t.data[e+8:]))
+			if start > 0 && start+size <= len(t.data) {
+				zero(t.data[start : start+size])
+			}
+		}
+	}
+	//条目数和下一个目录的偏移都为 0，即一个合法的空目录
+	zero(t.data[offset:end])
+}
+
+// tiff EXIF 中的 TIFF 数据，偏移都相对于 data 的开头
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// findTIFF 在 JPEG 的 APP1 段中查找 EXIF，返回的 data 与原数据共享内存
+func findTIFF(data []byte) *tiff {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return nil
+		}
+		marker := data[i+1]
+		//SOS 之后是图像数据，不会再有 APP 段
+		if marker == 0xda || marker == 0xd9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return nil
+		}
+
+		segment := data[i+4 : i+2+length]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return newTIFF(segment[6:])
+		}
+		i += 2 + length
+	}
+
+	return nil
+}
+
+func newTIFF(data []byte) *tiff {
+	if len(data) < 8 {
+		return nil
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return nil
+	}
+	if order.Uint16(data[2:]) != 42 {
+		return nil
+	}
+
+	return &tiff{data: data, order: order}
+}
+
+func (t *tiff) ifd0() int {
+	return int(t.order.Uint32(t.data[4:]))
+}
+
+// find 返回目录中指定标签条目的偏移，不存在时返回 -1
+func (t *tiff) find(ifd, tag int) int {
+	if ifd <= 0 || ifd+2 > len(t.data) {
+		return -1
+	}
+
+	n := int(t.order.Uint16(t.data[ifd:]))
+	for i := 0; i < n; i++ {
+		e := ifd + 2 + 12*i
+		if e+12 > len(t.data) {
+			return -1
+		}
+		if int(t.order.Uint16(t.data[e:])) == tag {
+			return e
+		}
+	}
+
+	return -1
+}
+
+func (t *tiff) valueSize(entry int) int {
+	typ := int(t.order.Uint16(t.data[entry+2:]))
+	if typ <= 0 || typ >= len(typeSizes) {
+		return 0
+	}
+
+	return typeSizes[typ] * int(t.order.Uint32(t.data[entry+4:]))
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,150 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// 测试用 TIFF 数据中各部分的偏移
+const (
+	testIFD0     = 8
+	testGPSIFD   = 38
+	testLatitude = 68
+	testTrailer  = 92
+)
+
+// exifTIFF 创建包含方向和 GPS 信息的 TIFF 数据，withGPS 为 false 时 IFD0 只有方向
+func exifTIFF(order binary.ByteOrder, orientation int, withGPS bool) []byte {
+	data := make([]byte, testTrailer, testTrailer+4)
+	if order == binary.LittleEndian {
+		copy(data, "II")
+	} else {
+		copy(data, "MM")
+	}
+	order.PutUint16(data[2:], 42)
+	order.PutUint32(data[4:], testIFD0)
+
+	entry := func(offset, tag, typ, count int) []byte {
+		order.PutUint16(data[offset:], uint16(tag))
+		order.PutUint16(data[offset+2:], uint16(typ))
+		order.PutUint32(data[offset+4:], uint32(count))
+		return data[offset+8 : offset+12]
+	}
+
+	//IFD0：方向、GPS IFD 的偏移
+	n := 1
+	if withGPS {
+		n = 2
+	}
+	order.PutUint16(data[testIFD0:], uint16(n))
+	order.PutUint16(entry(testIFD0+2, tagOrientation, 3, 1), uint16(orientation))
+	if withGPS {
+		order.PutUint32(entry(testIFD0+14, tagGPSInfo, 4, 1), testGPSIFD)
+
+		//GPS IFD：纬度方向（内联的 ASCII）、纬度（目录之外的 3 个 RATIONAL）
+		order.PutUint16(data[testGPSIFD:], 2)
+		copy(entry(testGPSIFD+2, 1, 2, 2), "N\x00")
+		order.PutUint32(entry(testGPSIFD+14, 2, 5, 3), testLatitude)
+		for i, v := range []uint32{31, 1, 14, 1, 2520, 100} {
+			order.PutUint32(data[testLatitude+4*i:], v)
+		}
+	}
+
+	return append(data, "keep"...)
+}
+
+// exifJPEG 在 JPEG 的 APP0 段之后插入 EXIF 段，tiff 为 nil 时不插入
+func exifJPEG(t *testing.T, tiff []byte) []byte {
+	t.Helper()
+
+	var img bytes.Buffer
+	if err := jpeg.Encode(&img, image.NewGray(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte{0xff, 0xd8}
+	segment := func(marker byte, payload []byte) {
+		data = append(data, 0xff, marker, 0, 0)
+		binary.BigEndian.PutUint16(data[len(data)-2:], uint16(len(payload)+2))
+		data = append(data, payload...)
+	}
+	segment(0xe0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	//不是 EXIF 的 APP1 段（XMP）需要跳过
+	segment(0xe1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"))
+	if tiff != nil {
+		segment(0xe1, append([]byte("Exif\x00\x00"), tiff...))
+	}
+
+	return append(data, img.Bytes()[2:]...)
+}
+
+func TestOrientation(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		for o := 1; o <= 8; o++ {
+			if got := Orientation(exifJPEG(t, exifTIFF(order, o, true))); got != o {
+				t.Errorf("%v Orientation() = %d, want %d", order, got, o)
+			}
+		}
+	}
+
+	valid := exifJPEG(t, exifTIFF(binary.BigEndian, 6, false))
+	badMagic := exifTIFF(binary.LittleEndian, 6, false)
+	badMagic[2] = 0
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no exif", exifJPEG(t, nil)},
+		{"orientation 0", exifJPEG(t, exifTIFF(binary.LittleEndian, 0, false))},
+		{"orientation 9", exifJPEG(t, exifTIFF(binary.LittleEndian, 9, false))},
+		{"bad tiff magic", exifJPEG(t, badMagic)},
+		{"truncated", valid[:60]},
+		{"not jpeg", []byte("\x89PNG\r\n\x1a\n")},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		if got := Orientation(tt.data); got != 1 {
+			t.Errorf("%s: Orientation() = %d, want 1", tt.name, got)
+		}
+	}
+}
+
+func TestStripGPS(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		tiff := exifTIFF(order, 6, true)
+		data := exifJPEG(t, tiff)
+		want := append([]byte(nil), data...)
+		start := bytes.Index(data, tiff)
+
+		StripGPS(data)
+
+		//GPS IFD 和目录之外的纬度都被清零，其他内容不变
+		zero(want[start+testGPSIFD : start+testTrailer])
+		if !bytes.Equal(data, want) {
+			t.Errorf("%v StripGPS() changed %x, want %x", order, data[start:start+len(tiff)], want[start:start+len(tiff)])
+		}
+		if got := Orientation(data); got != 6 {
+			t.Errorf("%v Orientation() after StripGPS = %d, want 6", order, got)
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(data)); err != nil {
+			t.Errorf("%v decode after StripGPS: %v", order, err)
+		}
+	}
+}
+
+func TestStripGPSWithoutGPS(t *testing.T) {
+	for _, data := range [][]byte{
+		exifJPEG(t, exifTIFF(binary.LittleEndian, 6, false)),
+		exifJPEG(t, nil),
+		[]byte("\x89PNG\r\n\x1a\n"),
+	} {
+		want := append([]byte(nil), data...)
+		StripGPS(data)
+		if !bytes.Equal(data, want) {
+			t.Errorf("StripGPS() changed data without GPS")
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package imaging
+
+import (
+	"fmt"
+	"golang.org/x/image/draw"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// 缩放版本支持的输出格式
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+// Size 返回按 EXIF 方向摆正后图片的宽高
+func Size(img image.Image, orientation int) (int, int) {
+	b := img.Bounds()
+	if orientation >= 5 {
+		return b.Dy(), b.Dx()
+	}
+
+	return b.Dx(), b.Dy()
+}
+
+// Thumbnail 将图片按方向摆正后缩放到 width 宽，高度按比例计算。
+// 先缩放再旋转，旋转只处理缩放后的小图。opaque 为 true 时透明部分以白色填充，用于输出 JPEG
+func Thumbnail(img image.Image, orientation, width int, opaque bool) image.Image {
+	w, h := Size(img, orientation)
+	height := h * width / w
+	if height < 1 {
+		height = 1
+	}
+
+	//缩放的是摆正前的图片，方向为 5-8 时宽高互换
+	dw, dh := width, height
+	if orientation >= 5 {
+		dw, dh = height, width
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	if opaque {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+
+	return Orient(dst, orientation)
+}
+
+// Orient 按 EXIF 方向（1-8）翻转、旋转图片
+func Orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], img.Pix[img.PixOffset(x, y):img.PixOffset(x, y)+4])
+		}
+	}
+
+	return dst
+}
+
+// Encode 按 format 编码图片，quality 只对 JPEG 有效，不大于 0 时使用默认质量
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case FormatJPEG:
+		if quality <= 0 {
+			quality = jpeg.DefaultQuality
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case FormatPNG:
+		return png.Encode(w, img)
+	}
+
+	return fmt.Errorf("imaging: unsupported format %q", format)
+}
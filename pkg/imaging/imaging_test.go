@@ -0,0 +1,144 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// labeled 创建宽 len(rows[0])、高 len(rows) 的图片，每个像素的 R 值为对应的字母
+func labeled(rows ...string) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x := range row {
+			img.SetNRGBA(x, y, color.NRGBA{R: row[x], A: 255})
+		}
+	}
+
+	return img
+}
+
+func labels(img *image.NRGBA) string {
+	b := img.Bounds()
+	rows := make([]string, 0, b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := make([]byte, 0, b.Dx())
+		for x := b.Min.X; x < b.Max.X; x++ {
+			row = append(row, img.NRGBAAt(x, y).R)
+		}
+		rows = append(rows, string(row))
+	}
+
+	return strings.Join(rows, "/")
+}
+
+func TestOrient(t *testing.T) {
+	//保存的图片为
+	//ABC
+	//DEF
+	tests := []struct {
+		orientation int
+		want        string
+	}{
+		{0, "ABC/DEF"},
+		{1, "ABC/DEF"},
+		//水平翻转
+		{2, "CBA/FED"},
+		//旋转180度
+		{3, "FED/CBA"},
+		//垂直翻转
+		{4, "DEF/ABC"},
+		//沿左上-右下对角线翻转
+		{5, "AD/BE/CF"},
+		//顺时针旋转90度
+		{6, "DA/EB/FC"},
+		//沿右上-左下对角线翻转
+		{7, "FC/EB/DA"},
+		//逆时针旋转90度
+		{8, "CF/BE/AD"},
+		{9, "ABC/DEF"},
+	}
+
+	for _, tt := range tests {
+		if got := labels(Orient(labeled("ABC", "DEF"), tt.orientation)); got != tt.want {
+			t.Errorf("Orient(%d) = %s, want %s", tt.orientation, got, tt.want)
+		}
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+
+	tests := []struct {
+		orientation int
+		width       int
+		wantW       int
+		wantH       int
+	}{
+		{1, 100, 100, 50},
+		{3, 100, 100, 50},
+		//方向为 5-8 时摆正后的图片为 200x400
+		{6, 100, 100, 200},
+		{8, 50, 50, 100},
+		//高度至少为 1
+		{1, 1, 1, 1},
+	}
+
+	for _, tt := range tests {
+		b := Thumbnail(src, tt.orientation, tt.width, false).Bounds()
+		if b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Errorf("Thumbnail(%d, %d) = %dx%d, want %dx%d", tt.orientation, tt.width, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestThumbnailOrientation(t *testing.T) {
+	//左半边红色、右半边蓝色，方向 6 顺时针旋转后红色在上方
+	src := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			src.SetNRGBA(x, y, c)
+		}
+	}
+
+	thumb := Thumbnail(src, 6, 10, false).(*image.NRGBA)
+	if top, bottom := thumb.NRGBAAt(5, 2), thumb.NRGBAAt(5, 17); top.R != 255 || bottom.B != 255 {
+		t.Errorf("top = %v, bottom = %v, want red above blue", top, bottom)
+	}
+}
+
+func TestThumbnailOpaque(t *testing.T) {
+	//完全透明的图片
+	src := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+
+	if c := Thumbnail(src, 1, 10, true).(*image.NRGBA).NRGBAAt(5, 5); c != (color.NRGBA{255, 255, 255, 255}) {
+		t.Errorf("opaque pixel = %v, want white", c)
+	}
+	if c := Thumbnail(src, 1, 10, false).(*image.NRGBA).NRGBAAt(5, 5); c.A != 0 {
+		t.Errorf("transparent pixel = %v, want alpha 0", c)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+
+	for format, want := range map[string]string{FormatJPEG: "jpeg", FormatPNG: "png"} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, img, format, 0); err != nil {
+			t.Fatal(err)
+		}
+		if _, got, err := image.DecodeConfig(&buf); err != nil || got != want {
+			t.Errorf("Encode(%s) decoded as %q, %v", format, got, err)
+		}
+	}
+
+	if err := Encode(&bytes.Buffer{}, img, "webp", 0); err == nil {
+		t.Error("Encode(webp) = nil error")
+	}
+}
@@ -0,0 +1,36 @@
+package markdown
+
+import "strings"
+
+// SplitFrontMatter 分离文件开头两行 --- 之间的 YAML front matter 和正文，没有 front matter 时 matter 为空
+func SplitFrontMatter(source string) (matter, body string) {
+	source = strings.TrimPrefix(source, "\uFEFF")
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	if !strings.HasPrefix(source, "---\n") {
+		return "", source
+	}
+
+	rest := source[len("---\n"):]
+	for offset := 0; offset <= len(rest); {
+		end := strings.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+
+		//Jekyll 也允许使用 ... 结束 front matter
+		if line == "---" || line == "..." {
+			body := ""
+			if end >= 0 {
+				body = rest[offset+end+1:]
+			}
+			return rest[:offset], strings.TrimLeft(body, "\n")
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+
+	return "", source
+}
@@ -0,0 +1,34 @@
+package markdown
+
+import "testing"
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		wantMatter string
+		wantBody   string
+	}{
+		{"none", "# title\nbody", "", "# title\nbody"},
+		{"dashes", "---\ntitle: a\n---\nbody", "title: a\n", "body"},
+		{"dots", "---\ntitle: a\n...\nbody", "title: a\n", "body"},
+		{"bom", "\uFEFF---\ntitle: a\n---\nbody", "title: a\n", "body"},
+		{"crlf", "---\r\ntitle: a\r\ntags: [x]\r\n---\r\nline 1\r\nline 2", "title: a\ntags: [x]\n", "line 1\nline 2"},
+		{"empty matter", "---\n---\nbody", "", "body"},
+		{"no body", "---\ntitle: a\n---", "title: a\n", ""},
+		{"blank lines after", "---\ntitle: a\n---\n\n\nbody\n", "title: a\n", "body\n"},
+		{"unterminated", "---\ntitle: a\nbody", "", "---\ntitle: a\nbody"},
+		{"not at start", "body\n---\ntitle: a\n---\n", "", "body\n---\ntitle: a\n---\n"},
+		{"dashes with text", "--- title\n---\nbody", "", "--- title\n---\nbody"},
+		{"horizontal rule in body", "---\ntitle: a\n---\nbody\n---\nmore", "title: a\n", "body\n---\nmore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matter, body := SplitFrontMatter(tt.source)
+			if matter != tt.wantMatter || body != tt.wantBody {
+				t.Errorf("SplitFrontMatter(%q) = %q, %q, want %q, %q", tt.source, matter, body, tt.wantMatter, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package markdown
+
+import (
+	"bytes"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TocItem 目录中的一个标题，Children 为其下级标题
+type TocItem struct {
+	Level    int        `json:"level"`
+	Title    string     `json:"title"`
+	ID       string     `json:"id"`
+	Children []*TocItem `json:"children,omitempty"`
+}
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(
+			//即 extension.GFM，表格对齐使用 align 属性，style 属性会被过滤掉
+			extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+			extension.Strikethrough,
+			extension.Linkify,
+			extension.TaskList,
+			extension.Footnote,
+			//代码块只输出 chroma 的 class，样式由前端的主题 CSS 决定
+			highlighting.NewHighlighting(
+				highlighting.WithFormatOptions(chromahtml.WithClasses(true)),
+			),
+		),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		//允许内容中的原始 HTML，最终输出统一经过 policy 过滤
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	policy = newPolicy()
+)
+
+// Render 将 Markdown 渲染为过滤后的 HTML，并提取各级标题作为目录
+func Render(source string) (string, []*TocItem, error) {
+	src := []byte(source)
+	ctx := parser.NewContext(parser.WithIDs(newIDs()))
+	doc := md.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return "", nil, err
+	}
+
+	return policy.Sanitize(buf.String()), toc(doc, src), nil
+}
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	//标题锚点和脚注的 id，标题中可能有中文
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}\-_:.]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6", "li", "sup")
+	//代码高亮、脚注等使用的 class
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^[\w\- ]+$`)).OnElements("pre", "code", "span", "div", "a")
+	p.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-[a-z]+$`)).OnElements("div", "a")
+	//GFM 任务列表
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return p
+}
+
+// toc 按标题出现的顺序生成嵌套的目录，标题级别跳跃时挂到最近的上级标题下
+func toc(doc ast.Node, src []byte) []*TocItem {
+	var (
+		items []*TocItem
+		stack []*TocItem
+	)
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		item := &TocItem{Level: heading.Level, Title: string(heading.Text(src))}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				item.ID = string(b)
+			}
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			items = append(items, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return items
+}
+
+// ids 生成标题锚点，goldmark 默认只保留 ASCII 字符，中文标题会全部变成 heading
+type ids struct {
+	used map[string]bool
+}
+
+func newIDs() *ids {
+	return &ids{used: make(map[string]bool)}
+}
+
+func (s *ids) Generate(value []byte, kind ast.NodeKind) []byte {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(string(value))) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			dash = false
+		case r == ' ' || r == '-' || r == '_':
+			if !dash && b.Len() > 0 {
+				b.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+
+	id := strings.TrimSuffix(b.String(), "-")
+	if id == "" {
+		id = "heading"
+	}
+
+	result := id
+	for i := 1; s.used[result]; i++ {
+		result = id + "-" + strconv.Itoa(i)
+	}
+	s.used[result] = true
+
+	return []byte(result)
+}
+
+func (s *ids) Put(value []byte) {
+	s.used[string(value)] = true
+}
@@ -0,0 +1,165 @@
+package markdown
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, source string) (string, []*TocItem) {
+	t.Helper()
+
+	html, toc, err := Render(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return html, toc
+}
+
+func TestRenderSanitize(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		//输出中不能出现的内容，不区分大小写
+		reject []string
+		//输出中需要保留的内容
+		keep []string
+	}{
+		{
+			name:   "script",
+			source: "<script>alert(1)</script>\n\ntext <script src=\"x.js\"></script>\n",
+			reject: []string{"<script", "alert(1)", "x.js"},
+			keep:   []string{"text"},
+		},
+		{
+			name:   "javascript links",
+			source: "[a](javascript:alert(1)) <a href=\"JaVaScRiPt:alert(2)\">b</a> [c](https://example.com)\n",
+			reject: []string{"javascript:", "alert"},
+			keep:   []string{`<a href="https://example.com" rel="nofollow">c</a>`},
+		},
+		{
+			name:   "event handlers",
+			source: "<img src=\"a.png\" onerror=\"alert(1)\"> <p onclick=\"alert(2)\" onmouseover=\"alert(3)\">p</p>\n",
+			reject: []string{"onerror", "onclick", "onmouseover", "alert"},
+			keep:   []string{`<img src="a.png">`, "<p>p</p>"},
+		},
+		{
+			name:   "style and iframe",
+			source: "<iframe src=\"https://example.com\"></iframe><span style=\"color:red\" class=\"a b\">s</span>\n",
+			reject: []string{"<iframe", "style="},
+			keep:   []string{`<span class="a b">s</span>`},
+		},
+		{
+			name:   "invalid class",
+			source: "<span class=\"a;b\">s</span> <h2 id=\"x\" onclick=\"alert(1)\">h</h2>\n",
+			reject: []string{"class=", "onclick"},
+			keep:   []string{"<span>s</span>", `<h2 id="x">h</h2>`},
+		},
+		{
+			name:   "highlight classes",
+			source: "```go\nfunc main() {}\n```\n",
+			reject: []string{"style="},
+			keep:   []string{`<pre class="chroma">`, `<span class="kd">func</span>`, `<span class="nf">main</span>`},
+		},
+		{
+			name:   "footnotes",
+			source: "text[^1]\n\n[^1]: note\n",
+			keep: []string{
+				`<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref"`,
+				`<div class="footnotes" role="doc-endnotes">`,
+				`<li id="fn:1">`,
+				`<a href="#fnref:1" class="footnote-backref" role="doc-backlink"`,
+			},
+		},
+		{
+			name:   "gfm",
+			source: "- [x] done\n\n| a | b |\n|:-|-:|\n| 1 | 2 |\n\n~~old~~\n",
+			keep:   []string{`<input checked="" disabled="" type="checkbox">`, `<th align="left">a</th>`, `<td align="right">2</td>`, "<del>old</del>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, _ := render(t, tt.source)
+			for _, s := range tt.reject {
+				if strings.Contains(strings.ToLower(html), strings.ToLower(s)) {
+					t.Errorf("output contains %q:\n%s", s, html)
+				}
+			}
+			for _, s := range tt.keep {
+				if !strings.Contains(html, s) {
+					t.Errorf("output does not contain %q:\n%s", s, html)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderHeadingIDs(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{"ascii", "# Hello, World!\n", []string{"hello-world"}},
+		{"chinese", "# 你好 世界\n", []string{"你好-世界"}},
+		{"mixed", "# Go 语言_入门 -- 第1章\n", []string{"go-语言-入门-第1章"}},
+		{"other scripts", "# Привет мир\n## こんにちは\n", []string{"привет-мир", "こんにちは"}},
+		{"duplicates", "# 标题\n## 标题\n### 标题\n", []string{"标题", "标题-1", "标题-2"}},
+		{"symbols only", "# !!!\n## ???\n# heading\n", []string{"heading", "heading-1", "heading-2"}},
+		//生成的 id 不能与已有的重复
+		{"collision", "# a-1\n## a\n## a\n", []string{"a-1", "a", "a-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html, toc := render(t, tt.source)
+
+			var got []string
+			var walk func(items []*TocItem)
+			walk = func(items []*TocItem) {
+				for _, item := range items {
+					got = append(got, item.ID)
+					walk(item.Children)
+				}
+			}
+			walk(toc)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("toc ids = %v, want %v", got, tt.want)
+			}
+			for _, id := range tt.want {
+				if !strings.Contains(html, ` id="`+id+`"`) {
+					t.Errorf("output does not contain id %q:\n%s", id, html)
+				}
+			}
+		})
+	}
+
+	//每次渲染的 id 互不影响
+	if _, toc := render(t, "# 标题\n"); toc[0].ID != "标题" {
+		t.Errorf("id = %q in a new render, want 标题", toc[0].ID)
+	}
+}
+
+func TestRenderToc(t *testing.T) {
+	source := "# A\n\n## B\n\n#### C\n\n### D\n\n## E *em* `code`\n\n# F\n\ntext\n\n### G\n"
+	_, toc := render(t, source)
+
+	got, err := json.Marshal(toc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//标题级别跳跃时挂到最近的上级标题下
+	want := `[{"level":1,"title":"A","id":"a","children":[` +
+		`{"level":2,"title":"B","id":"b","children":[{"level":4,"title":"C","id":"c"},{"level":3,"title":"D","id":"d"}]},` +
+		`{"level":2,"title":"E em code","id":"e-em-code"}]},` +
+		`{"level":1,"title":"F","id":"f","children":[{"level":3,"title":"G","id":"g"}]}]`
+	if string(got) != want {
+		t.Errorf("toc =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, toc := render(t, "text only\n"); len(toc) != 0 {
+		t.Errorf("toc = %v, want empty", toc)
+	}
+}
@@ -0,0 +1,226 @@
+package poster
+
+import (
+	"bytes"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// 没有配置背景模板时使用的海报尺寸
+const (
+	defaultWidth  = 750
+	defaultHeight = 1334
+)
+
+var (
+	titleColor   = color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff}
+	descColor    = color.RGBA{R: 0x88, G: 0x88, B: 0x88, A: 0xff}
+	defaultColor = color.RGBA{R: 0xf7, G: 0xf7, B: 0xf7, A: 0xff}
+)
+
+// Poster 分享海报的内容，Cover 为 nil 时不绘制封面
+type Poster struct {
+	Title   string
+	Desc    string
+	Cover   image.Image
+	QrCode  image.Image
+	Caption string
+}
+
+func GetPosterFullUrl(name string) string {
+	return storage.Default.URL(GetPosterPath() + name)
+}
+
+func GetPosterPath() string {
+	return setting.AppSetting.PosterSavePath
+}
+
+// Draw 在背景模板上依次绘制封面、标题、简介和底部的二维码，各元素的尺寸按背景宽度等比例计算
+func (p *Poster) Draw() (*image.RGBA, error) {
+	bg, err := loadTemplate()
+	if err != nil {
+		return nil, err
+	}
+	face, err := loadFont()
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := bg.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), bg, bounds.Min, draw.Src)
+
+	margin := width / 15
+	contentWidth := width - 2*margin
+	y := margin
+
+	if p.Cover != nil {
+		rect := image.Rect(margin, y, margin+contentWidth, y+contentWidth*9/16)
+		draw.CatmullRom.Scale(dst, rect, p.Cover, coverCrop(p.Cover.Bounds(), rect), draw.Over, nil)
+		y = rect.Max.Y + margin
+	}
+
+	titleFace, err := opentype.NewFace(face, &opentype.FaceOptions{Size: float64(width) / 18, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer titleFace.Close()
+	y = drawText(dst, titleFace, titleColor, p.Title, margin, y, contentWidth, 3)
+
+	smallFace, err := opentype.NewFace(face, &opentype.FaceOptions{Size: float64(width) / 28, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	defer smallFace.Close()
+	if p.Desc != "" {
+		drawText(dst, smallFace, descColor, p.Desc, margin, y+margin/2, contentWidth, 2)
+	}
+
+	if p.QrCode != nil {
+		size := width / 3
+		captionHeight := 0
+		if p.Caption != "" {
+			captionHeight = smallFace.Metrics().Height.Ceil() + margin/4
+		}
+		top := height - margin - captionHeight - size
+		rect := image.Rect((width-size)/2, top, (width+size)/2, top+size)
+		draw.NearestNeighbor.Scale(dst, rect, p.QrCode, p.QrCode.Bounds(), draw.Over, nil)
+
+		if p.Caption != "" {
+			captionWidth := font.MeasureString(smallFace, p.Caption).Ceil()
+			drawText(dst, smallFace, descColor, p.Caption, (width-captionWidth)/2, rect.Max.Y+margin/4, contentWidth, 1)
+		}
+	}
+
+	return dst, nil
+}
+
+// Save 将海报编码为 PNG 保存到 GetPosterPath() 下
+func Save(img image.Image, name string) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+
+	return storage.Default.Put(GetPosterPath()+name, &buf, "image/png")
+}
+
+// 没有配置 PosterTemplate 时使用纯色背景
+func loadTemplate() (image.Image, error) {
+	if setting.AppSetting.PosterTemplate == "" {
+		bg := image.NewRGBA(image.Rect(0, 0, defaultWidth, defaultHeight))
+		draw.Draw(bg, bg.Bounds(), image.NewUniform(defaultColor), image.Point{}, draw.Src)
+		return bg, nil
+	}
+
+	f, err := os.Open(setting.AppSetting.PosterTemplate)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// 没有配置 PosterFont 时使用 Go 字体，Go 字体不包含中文，中文标题需要配置支持中文的字体
+func loadFont() (*opentype.Font, error) {
+	data := gobold.TTF
+	if setting.AppSetting.PosterFont != "" {
+		var err error
+		if data, err = ioutil.ReadFile(setting.AppSetting.PosterFont); err != nil {
+			return nil, err
+		}
+	}
+
+	//同时支持 ttf、otf 和 ttc，字体集合使用其中的第一个字体
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return collection.Font(0)
+}
+
+// coverCrop 从封面中间截取与目标区域宽高比相同的部分，缩放后铺满目标区域
+func coverCrop(src, dst image.Rectangle) image.Rectangle {
+	w, h := src.Dx(), src.Dy()
+	if w*dst.Dy() > h*dst.Dx() {
+		cw := h * dst.Dx() / dst.Dy()
+		x := src.Min.X + (w-cw)/2
+		return image.Rect(x, src.Min.Y, x+cw, src.Max.Y)
+	}
+
+	ch := w * dst.Dy() / dst.Dx()
+	y := src.Min.Y + (h-ch)/2
+	return image.Rect(src.Min.X, y, src.Max.X, y+ch)
+}
+
+// drawText 从 (x, y) 开始按 maxWidth 自动换行绘制文字，最多 maxLines 行，超出部分以省略号结尾，返回最后一行底部的纵坐标
+func drawText(dst draw.Image, face font.Face, c color.Color, text string, x, y, maxWidth, maxLines int) int {
+	lines := wrap(face, text, maxWidth)
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+		lines[maxLines-1] = ellipsis(face, lines[maxLines-1], maxWidth)
+	}
+
+	metrics := face.Metrics()
+	lineHeight := metrics.Height.Ceil() * 5 / 4
+	d := &font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: face}
+	for _, line := range lines {
+		d.Dot = fixed.P(x, y+metrics.Ascent.Ceil())
+		d.DrawString(line)
+		y += lineHeight
+	}
+
+	return y
+}
+
+func wrap(face font.Face, text string, maxWidth int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.TrimSpace(text), "\n") {
+		line := ""
+		for _, r := range strings.TrimSpace(paragraph) {
+			if line != "" && font.MeasureString(face, line+string(r)).Ceil() > maxWidth {
+				//英文单词不从中间断开，整个单词移到下一行
+				next := ""
+				if i := strings.LastIndexByte(line, ' '); i > 0 && r != ' ' && line[len(line)-1] != ' ' {
+					line, next = line[:i], line[i+1:]
+				}
+				lines = append(lines, strings.TrimRight(line, " "))
+				line = next
+				if r == ' ' {
+					continue
+				}
+			}
+			line += string(r)
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func ellipsis(face font.Face, line string, maxWidth int) string {
+	runes := []rune(line)
+	for len(runes) > 0 && font.MeasureString(face, string(runes)+"…").Ceil() > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
@@ -0,0 +1,17 @@
+package qrcode
+
+import (
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"image"
+)
+
+// Encode 生成内容为 content、边长为 size 像素的二维码
+func Encode(content string, size int) (image.Image, error) {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return nil, err
+	}
+
+	return barcode.Scale(code, size, size)
+}
@@ -0,0 +1,45 @@
+package rbac
+
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleAuthor = "author"
+	RoleReader = "reader"
+)
+
+const (
+	// 新建文章，修改、删除自己创建的文章
+	PermWriteArticle = "article:write"
+	// 修改、删除任意文章
+	PermManageArticle = "article:manage"
+	// 新建、修改、删除、导入导出标签
+	PermManageTag = "tag:manage"
+	// 管理用户账号
+	PermManageUser = "user:manage"
+	// 查看、手动执行定时任务
+	PermManageSystem = "system:manage"
+)
+
+var rolePermissions = map[string][]string{
+	RoleAdmin:  {PermWriteArticle, PermManageArticle, PermManageTag, PermManageUser, PermManageSystem},
+	RoleEditor: {PermWriteArticle, PermManageArticle, PermManageTag},
+	RoleAuthor: {PermWriteArticle},
+	RoleReader: {},
+}
+
+// IsValidRole 检查角色是否存在
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission 检查角色是否拥有指定权限
+func HasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
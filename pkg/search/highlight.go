@@ -0,0 +1,82 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Highlight 截取 text 中第一个命中位置附近最多 size 个字符作为摘要，命中的检索词用 <em> 标出，
+// 其余内容做 HTML 转义；size 小于等于 0 时返回全文，没有命中时返回开头的内容
+func Highlight(text string, terms []string, size int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	//标记每个字符是否属于某个检索词，相邻、重叠的检索词会连成一段
+	marked := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		t := []rune(strings.ToLower(term))
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if equalRunes(lower[i:i+len(t)], t) {
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+				if first == -1 || i < first {
+					first = i
+				}
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if size > 0 && len(runes) > size {
+		if first > size/4 {
+			start = first - size/4
+		}
+		end = start + size
+		if end > len(runes) {
+			end = len(runes)
+			start = end - size
+		}
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marked[j] == marked[i] {
+			j++
+		}
+		segment := html.EscapeString(string(runes[i:j]))
+		if marked[i] {
+			sb.WriteString("<em>" + segment + "</em>")
+		} else {
+			sb.WriteString(segment)
+		}
+		i = j
+	}
+	if end < len(runes) {
+		sb.WriteString("…")
+	}
+
+	return sb.String()
+}
+
+func equalRunes(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
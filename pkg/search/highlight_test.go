@@ -0,0 +1,32 @@
+package search
+
+import "testing"
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		size  int
+		want  string
+	}{
+		{"no terms", "a <b> & c", nil, 0, "a &lt;b&gt; &amp; c"},
+		{"no match", "hello", []string{"go"}, 0, "hello"},
+		{"case insensitive", "Go and go", []string{"go"}, 0, "<em>Go</em> and <em>go</em>"},
+		{"escape around match", `<script>go("&")</script>`, []string{"go"}, 0, "&lt;script&gt;<em>go</em>(&#34;&amp;&#34;)&lt;/script&gt;"},
+		{"escape inside match", "a<b>c", []string{"a<b"}, 0, "<em>a&lt;b</em>&gt;c"},
+		{"overlapping terms", "Go语言入门", []string{"go", "语言", "言入"}, 0, "<em>Go语言入</em>门"},
+		{"empty term", "abc", []string{""}, 0, "abc"},
+		{"snippet", "0123456789go0123456789", []string{"go"}, 8, "…89<em>go</em>0123…"},
+		{"snippet at start", "go0123456789", []string{"go"}, 4, "<em>go</em>01…"},
+		{"snippet at end", "0123456789go", []string{"go"}, 4, "…89<em>go</em>"},
+		{"snippet without match", "0123456789", []string{"go"}, 4, "0123…"},
+		{"snippet counts runes", "中文中文中文", nil, 2, "中文…"},
+	}
+
+	for _, tt := range tests {
+		if got := Highlight(tt.text, tt.terms, tt.size); got != tt.want {
+			t.Errorf("%s: Highlight() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
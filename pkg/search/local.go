@@ -0,0 +1,176 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 参数
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// 各字段的权重，标题命中比正文命中更相关
+var fieldWeights = []float64{3, 2, 1}
+
+// Local 进程内的倒排索引，使用 BM25 排序，启动时需要从数据库重建
+type Local struct {
+	mu sync.RWMutex
+	//docs 保存文章的筛选条件和加权后的长度
+	docs map[int]*localDoc
+	//postings 保存每个词在各文章中加权后的词频
+	postings map[string]map[int]float64
+	totalLen float64
+}
+
+type localDoc struct {
+	doc    Document
+	length float64
+	terms  []string
+}
+
+func NewLocal() *Local {
+	return &Local{
+		docs:     make(map[int]*localDoc),
+		postings: make(map[string]map[int]float64),
+	}
+}
+
+func (l *Local) Index(doc *Document) error {
+	freqs := make(map[string]float64)
+	length := 0.0
+	for i, field := range []string{doc.Title, doc.Desc, doc.Content} {
+		for _, token := range tokenize(field, true) {
+			freqs[token] += fieldWeights[i]
+			length += fieldWeights[i]
+		}
+	}
+
+	//正文不需要保存，结果中的摘要由调用方根据原文生成
+	d := &localDoc{doc: *doc, length: length, terms: make([]string, 0, len(freqs))}
+	d.doc.Title, d.doc.Desc, d.doc.Content = "", "", ""
+	d.doc.TagIDs = append([]int(nil), doc.TagIDs...)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.remove(doc.ID)
+	for term, freq := range freqs {
+		postings, ok := l.postings[term]
+		if !ok {
+			postings = make(map[int]float64)
+			l.postings[term] = postings
+		}
+		postings[doc.ID] = freq
+		d.terms = append(d.terms, term)
+	}
+	l.docs[doc.ID] = d
+	l.totalLen += length
+
+	return nil
+}
+
+func (l *Local) Delete(id int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.remove(id)
+	return nil
+}
+
+func (l *Local) remove(id int) {
+	d, ok := l.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range d.terms {
+		delete(l.postings[term], id)
+		if len(l.postings[term]) == 0 {
+			delete(l.postings, term)
+		}
+	}
+	delete(l.docs, id)
+	l.totalLen -= d.length
+}
+
+// Search 返回包含所有检索词的文章
+func (l *Local) Search(q *Query) ([]Hit, int, error) {
+	terms := unique(Tokenize(q.Text))
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	lists := make([]map[int]float64, 0, len(terms))
+	for _, term := range terms {
+		postings, ok := l.postings[term]
+		if !ok {
+			return nil, 0, nil
+		}
+		lists = append(lists, postings)
+	}
+	//从最短的倒排列表开始求交集
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	n := float64(len(l.docs))
+	avgLen := l.totalLen / n
+	var hits []Hit
+	for id := range lists[0] {
+		d := l.docs[id]
+		if !d.doc.match(q) {
+			continue
+		}
+
+		score := 0.0
+		matched := true
+		for _, postings := range lists {
+			tf, ok := postings[id]
+			if !ok {
+				matched = false
+				break
+			}
+			df := float64(len(postings))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*d.length/avgLen))
+		}
+		if matched {
+			hits = append(hits, Hit{ID: id, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+
+	total := len(hits)
+	if q.PageNum >= total {
+		return nil, total, nil
+	}
+	end := total
+	if q.PageSize > 0 && q.PageNum+q.PageSize < total {
+		end = q.PageNum + q.PageSize
+	}
+
+	return hits[q.PageNum:end], total, nil
+}
+
+func unique(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	result := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			result = append(result, term)
+		}
+	}
+
+	return result
+}
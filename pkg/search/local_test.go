@@ -0,0 +1,262 @@
+package search
+
+import (
+	"testing"
+)
+
+func newTestLocal(t *testing.T, docs ...*Document) *Local {
+	t.Helper()
+
+	l := NewLocal()
+	for _, doc := range docs {
+		if doc.State == 0 {
+			doc.State = 1
+		}
+		if err := l.Index(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return l
+}
+
+func searchIDs(t *testing.T, l *Local, q *Query) []int {
+	t.Helper()
+
+	hits, total, err := l.Search(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, 0, len(hits))
+	for i, hit := range hits {
+		if i > 0 && hit.Score > hits[i-1].Score {
+			t.Errorf("hits are not sorted by score: %v", hits)
+		}
+		ids = append(ids, hit.ID)
+	}
+	if q.PageSize == 0 && total != len(hits) {
+		t.Errorf("total = %d, want %d", total, len(hits))
+	}
+
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestLocalRanking(t *testing.T) {
+	tests := []struct {
+		name string
+		docs []*Document
+		q    string
+		want []int
+	}{
+		{
+			//标题的权重高于简介，简介高于正文
+			name: "field weight",
+			docs: []*Document{
+				{ID: 1, Title: "note", Desc: "note", Content: "golang"},
+				{ID: 2, Title: "golang", Desc: "note", Content: "note"},
+				{ID: 3, Title: "note", Desc: "golang", Content: "note"},
+			},
+			q:    "golang",
+			want: []int{2, 3, 1},
+		},
+		{
+			name: "term frequency",
+			docs: []*Document{
+				{ID: 1, Content: "go one two three"},
+				{ID: 2, Content: "go go go three"},
+				{ID: 3, Content: "go go two three"},
+			},
+			q:    "go",
+			want: []int{2, 3, 1},
+		},
+		{
+			//词频相同时较短的文章更相关
+			name: "document length",
+			docs: []*Document{
+				{ID: 1, Content: "go one two three four five"},
+				{ID: 2, Content: "go"},
+				{ID: 3, Content: "go one two"},
+			},
+			q:    "go",
+			want: []int{2, 3, 1},
+		},
+		{
+			name: "equal scores by id",
+			docs: []*Document{
+				{ID: 1, Content: "go"},
+				{ID: 3, Content: "go"},
+				{ID: 2, Content: "go"},
+			},
+			q:    "go",
+			want: []int{3, 2, 1},
+		},
+		{
+			//所有检索词都需要命中
+			name: "all terms",
+			docs: []*Document{
+				{ID: 1, Content: "gin framework"},
+				{ID: 2, Content: "gin"},
+				{ID: 3, Content: "framework gin tutorial"},
+			},
+			q:    "Gin Framework",
+			want: []int{1, 3},
+		},
+		{
+			name: "mixed cjk and latin",
+			docs: []*Document{
+				{ID: 1, Title: "Gin框架入门"},
+				{ID: 2, Title: "Gin 与 Beego"},
+				{ID: 3, Title: "框架对比"},
+			},
+			q:    "gin框架",
+			want: []int{1},
+		},
+		{
+			name: "cjk unigram",
+			docs: []*Document{
+				{ID: 1, Content: "Go语言"},
+				{ID: 2, Content: "言论"},
+				{ID: 3, Content: "语法"},
+			},
+			q:    "语",
+			want: []int{3, 1},
+		},
+		{
+			name: "cjk bigram does not match across words",
+			docs: []*Document{
+				{ID: 1, Content: "语 言"},
+				{ID: 2, Content: "语言"},
+			},
+			q:    "语言",
+			want: []int{2},
+		},
+		{"no terms", []*Document{{ID: 1, Content: "go"}}, "，。!", []int{}},
+		{"unknown term", []*Document{{ID: 1, Content: "go"}}, "rust", []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestLocal(t, tt.docs...)
+			if got := searchIDs(t, l, &Query{Text: tt.q, State: -1}); !equalIDs(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.q, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalReindex(t *testing.T) {
+	l := newTestLocal(t,
+		&Document{ID: 1, Title: "old title", Content: "shared"},
+		&Document{ID: 2, Title: "other", Content: "shared"},
+	)
+
+	//修改后旧的词不再命中
+	if err := l.Index(&Document{ID: 1, Title: "new title", Content: "shared", State: 1}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		q    string
+		want []int
+	}{
+		{"old", []int{}},
+		{"new", []int{1}},
+		{"shared", []int{2, 1}},
+	}
+	for _, tt := range tests {
+		if got := searchIDs(t, l, &Query{Text: tt.q, State: -1}); !equalIDs(got, tt.want) {
+			t.Errorf("after edit Search(%q) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+
+	if err := l.Delete(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchIDs(t, l, &Query{Text: "shared", State: -1}); !equalIDs(got, []int{2}) {
+		t.Errorf("after delete Search(shared) = %v, want [2]", got)
+	}
+	if _, ok := l.postings["new"]; ok {
+		t.Error("postings of the deleted document were kept")
+	}
+	//删除不存在的文章不影响索引
+	if err := l.Delete(100); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.docs) != 0 || len(l.postings) != 0 || l.totalLen != 0 {
+		t.Errorf("index is not empty after deleting every document: %d docs, %d terms, length %v", len(l.docs), len(l.postings), l.totalLen)
+	}
+}
+
+// 重复建立索引不改变评分
+func TestLocalReindexScore(t *testing.T) {
+	doc := &Document{ID: 1, Title: "go", Content: "go tutorial", State: 1}
+	l := newTestLocal(t, doc, &Document{ID: 2, Content: "go"})
+
+	before, _, _ := l.Search(&Query{Text: "go", State: -1})
+	if err := l.Index(doc); err != nil {
+		t.Fatal(err)
+	}
+	after, _, _ := l.Search(&Query{Text: "go", State: -1})
+	if len(before) != 2 || len(after) != 2 || before[0] != after[0] || before[1] != after[1] {
+		t.Errorf("Search() = %v after reindex, want %v", after, before)
+	}
+}
+
+func TestLocalFilters(t *testing.T) {
+	l := newTestLocal(t,
+		&Document{ID: 1, Content: "go", TagIDs: []int{1, 2}},
+		&Document{ID: 2, Content: "go", TagIDs: []int{2}},
+		&Document{ID: 3, Content: "go", State: 2},
+		&Document{ID: 4, Content: "go", PublishAt: 100, UnpublishAt: 200},
+	)
+
+	tests := []struct {
+		name string
+		q    Query
+		want []int
+	}{
+		{"any state", Query{State: -1}, []int{4, 3, 2, 1}},
+		{"state", Query{State: 2}, []int{3}},
+		{"any tag", Query{State: -1, TagIDs: []int{1, 2}}, []int{2, 1}},
+		{"all tags", Query{State: -1, TagIDs: []int{1, 2}, MatchAllTags: true}, []int{1}},
+		{"published", Query{State: -1, PublishedAt: 150}, []int{4, 2, 1}},
+		{"unpublished", Query{State: -1, PublishedAt: 200}, []int{2, 1}},
+		{"page", Query{State: -1, PageNum: 1, PageSize: 2}, []int{3, 2}},
+		{"page out of range", Query{State: -1, PageNum: 4, PageSize: 2}, []int{}},
+	}
+
+	for _, tt := range tests {
+		q := tt.q
+		q.Text = "go"
+		hits, total, err := l.Search(&q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]int, 0, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+		if !equalIDs(ids, tt.want) {
+			t.Errorf("%s: Search() = %v, want %v", tt.name, ids, tt.want)
+		}
+		if q.PageSize > 0 && total != 4 {
+			t.Errorf("%s: total = %d, want 4", tt.name, total)
+		}
+	}
+}
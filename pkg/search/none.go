@@ -0,0 +1,20 @@
+package search
+
+// None 不建立索引，搜索结果始终为空
+type None struct{}
+
+func NewNone() None {
+	return None{}
+}
+
+func (None) Index(doc *Document) error {
+	return nil
+}
+
+func (None) Delete(id int) error {
+	return nil
+}
+
+func (None) Search(q *Query) ([]Hit, int, error) {
+	return nil, 0, nil
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"fmt"
+	"gin-blog/pkg/setting"
+)
+
+// Document 建立索引的文章
+type Document struct {
+	ID          int
+	Title       string
+	Desc        string
+	Content     string
+	TagIDs      []int
+	State       int
+	PublishAt   int
+	UnpublishAt int
+}
+
+// Query 搜索条件，State 为 -1 时不按状态筛选，PublishedAt 大于 0 时只返回该时刻已发布的文章
+type Query struct {
+	Text         string
+	TagIDs       []int
+	MatchAllTags bool
+	State        int
+	PublishedAt  int
+
+	PageNum  int
+	PageSize int
+}
+
+// Hit 一条搜索结果，按 Score 从高到低排列
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Index 文章的全文索引，文章写入后由 article_service 同步
+type Index interface {
+	Index(doc *Document) error
+	Delete(id int) error
+	// Search 返回当前页的结果和结果总数
+	Search(q *Query) ([]Hit, int, error)
+}
+
+// New 根据配置 [search] Type 创建索引，mysql 索引依赖文章表，由 article_service.NewIndex 创建
+func New() (Index, error) {
+	switch setting.SearchSetting.Type {
+	case "", "local":
+		return NewLocal(), nil
+	case "none":
+		return NewNone(), nil
+	}
+
+	return nil, fmt.Errorf("search: unknown index type %q", setting.SearchSetting.Type)
+}
+
+// published 与 models.Article.IsPublished 的规则保持一致
+func (d *Document) published(now int) bool {
+	if d.PublishAt > 0 {
+		if d.PublishAt > now {
+			return false
+		}
+	} else if d.State != 1 {
+		return false
+	}
+
+	return d.UnpublishAt == 0 || d.UnpublishAt > now
+}
+
+func (d *Document) match(q *Query) bool {
+	if q.State >= 0 && d.State != q.State {
+		return false
+	}
+	if q.PublishedAt > 0 && !d.published(q.PublishedAt) {
+		return false
+	}
+	if len(q.TagIDs) == 0 {
+		return true
+	}
+
+	tags := make(map[int]bool, len(d.TagIDs))
+	for _, id := range d.TagIDs {
+		tags[id] = true
+	}
+	for _, id := range q.TagIDs {
+		if tags[id] && !q.MatchAllTags {
+			return true
+		}
+		if !tags[id] && q.MatchAllTags {
+			return false
+		}
+	}
+
+	return q.MatchAllTags
+}
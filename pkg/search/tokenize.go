@@ -0,0 +1,59 @@
+package search
+
+import "unicode"
+
+// Tokenize 将搜索词切分为检索词：英文、数字按单词切分并转为小写，中日韩文字没有分隔符，按相邻两个字切分，
+// 只有一个字时保留单字
+func Tokenize(s string) []string {
+	return tokenize(s, false)
+}
+
+// 建立索引时中日韩文字同时保留单字，使单字的搜索词也能命中
+func tokenize(s string, unigrams bool) []string {
+	var (
+		tokens []string
+		word   []rune
+		cjk    []rune
+	)
+
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 || (unigrams && len(cjk) > 0) {
+			for _, r := range cjk {
+				tokens = append(tokens, string(r))
+			}
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range s {
+		r = unicode.ToLower(r)
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
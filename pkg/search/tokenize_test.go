@@ -0,0 +1,49 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", ""},
+		{"Hello, World!", "hello|world"},
+		{"gin-blog v1.2", "gin|blog|v1|2"},
+		{"中", "中"},
+		{"语言", "语言"},
+		{"Go语言入门GO", "go|语言|言入|入门|go"},
+		{"Gin 框架，快速入门", "gin|框架|快速|速入|入门"},
+		{"ひらがなカタカナ", "ひら|らが|がな|なカ|カタ|タカ|カナ"},
+		{"한국어", "한국|국어"},
+		{"Ünïcode ÀB", "ünïcode|àb"},
+		{"中 文", "中|文"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(Tokenize(tt.s), "|"); got != tt.want {
+			t.Errorf("Tokenize(%q) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
+
+// 建立索引时保留单字
+func TestTokenizeUnigrams(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"语言", "语|言|语言"},
+		{"Go语言", "go|语|言|语言"},
+		{"中", "中"},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(tokenize(tt.s, true), "|"); got != tt.want {
+			t.Errorf("tokenize(%q, true) = %s, want %s", tt.s, got, tt.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package setting
 import (
 	"github.com/go-ini/ini"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -11,10 +12,16 @@ type App struct {
 	PageSize        int
 	RuntimeRootPath string
 
+	JwtExpire          time.Duration
+	RefreshTokenExpire time.Duration
+
 	PrefixUrl      string
 	ImageSavePath  string
 	ImageMaxSize   int
 	ImageAllowExts []string
+	//上传图片的最大宽度和高度，防止解码时占用过多内存
+	ImageMaxWidth  int
+	ImageMaxHeight int
 
 	LogSavePath string
 	LogSaveName string
@@ -22,6 +29,25 @@ type App struct {
 	TimeFormat  string
 
 	ExportSavePath string
+	//导入文章时上传的 zip 压缩包的最大大小
+	ImportMaxSize int
+
+	//文章分享海报的保存路径、背景模板和字体，模板和字体为空时使用默认值
+	PosterSavePath string
+	PosterTemplate string
+	PosterFont     string
+	//每个用户每分钟最多生成海报的次数，0 为不限制
+	PosterRateLimit int
+
+	//软删除的文章、标签在回收站中保留的天数，超过后会被彻底删除
+	TrashRetentionDays int
+
+	//没有被文章引用的上传文件超过该天数后视为孤立文件，为 false 时只记录日志不删除
+	MediaOrphanDays   int
+	MediaOrphanRemove bool
+
+	//公开接口响应的 Cache-Control max-age
+	PublicMaxAge time.Duration
 }
 
 var AppSetting = &App{}
@@ -56,6 +82,80 @@ type Redis struct {
 
 var RedisSetting = &Redis{}
 
+type Cache struct {
+	//redis、memory或none
+	Type       string
+	MemorySize int
+}
+
+var CacheSetting = &Cache{}
+
+type Search struct {
+	//local或mysql
+	Type string
+}
+
+var SearchSetting = &Search{}
+
+type Site struct {
+	Title       string
+	Description string
+	//订阅中包含的最新文章数量
+	FeedSize int
+	//robots.txt 中禁止爬虫访问的路径
+	RobotsDisallow []string
+	//订阅、站点地图和海报中文章、标签页面的路径，{id} 替换为ID，可以是绝对地址
+	ArticlePath string
+	TagPath     string
+}
+
+var SiteSetting = &Site{}
+
+type Image struct {
+	//上传图片时生成的缩放版本，格式为 名称:宽度，不生成比原图更宽的版本
+	Variants []string
+	//缩放版本的格式，jpeg或png，为空时 JPEG 原图使用jpeg，其他使用png
+	Format string
+	//JPEG质量，1-100
+	Quality int
+}
+
+var ImageSetting = &Image{}
+
+type Storage struct {
+	//local或s3，多实例部署时使用s3
+	Type string
+	//S3兼容存储的地址、区域、存储桶和密钥，MinIO需要开启PathStyle
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+	//文件的访问地址前缀，如CDN地址，为空时使用存储桶的地址
+	PublicUrl string
+}
+
+var StorageSetting = &Storage{}
+
+type Scheduler struct {
+	Enabled bool
+	//多实例部署时通过Redis锁保证每个任务只在一个实例上执行
+	DistributedLock bool
+	LockTTL         time.Duration
+}
+
+var SchedulerSetting = &Scheduler{}
+
+// Job 对应配置文件中的 [job.<name>]
+type Job struct {
+	Name    string `ini:"-"`
+	Spec    string
+	Enabled bool
+}
+
+var JobSettings []*Job
+
 var cfg *ini.File
 
 func Setup() {
@@ -69,11 +169,31 @@ func Setup() {
 	mapTo("server", ServerSetting)
 	mapTo("database", DatabaseSetting)
 	mapTo("redis", RedisSetting)
+	mapTo("cache", CacheSetting)
+	mapTo("search", SearchSetting)
+	mapTo("site", SiteSetting)
+	mapTo("image", ImageSetting)
+	mapTo("storage", StorageSetting)
+	mapTo("scheduler", SchedulerSetting)
+
+	JobSettings = nil
+	for _, section := range cfg.Section("job").ChildSections() {
+		job := &Job{Name: strings.TrimPrefix(section.Name(), "job.")}
+		if err := section.MapTo(job); err != nil {
+			log.Fatalf("Cfg.MapTo %s err: %v", section.Name(), err)
+		}
+		JobSettings = append(JobSettings, job)
+	}
 
 	AppSetting.ImageMaxSize = AppSetting.ImageMaxSize * 1024 * 1024
+	AppSetting.ImportMaxSize = AppSetting.ImportMaxSize * 1024 * 1024
+	AppSetting.JwtExpire = AppSetting.JwtExpire * time.Minute
+	AppSetting.PublicMaxAge = AppSetting.PublicMaxAge * time.Second
+	AppSetting.RefreshTokenExpire = AppSetting.RefreshTokenExpire * time.Minute
 	ServerSetting.ReadTimeout = ServerSetting.ReadTimeout * time.Second
 	ServerSetting.WriteTimeout = ServerSetting.ReadTimeout * time.Second
 	RedisSetting.IdleTimeout = RedisSetting.IdleTimeout * time.Second
+	SchedulerSetting.LockTTL = SchedulerSetting.LockTTL * time.Second
 }
 
 // mapTo map section
@@ -0,0 +1,82 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// MaxURLs 单个站点地图文件最多包含的地址数，超过后需要拆分并使用索引文件
+const MaxURLs = 50000
+
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL 站点地图中的一个地址，LastMod 为零值时不输出
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type urlset struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []entry  `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []entry  `xml:"sitemap"`
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet 生成包含 urls 的站点地图
+func URLSet(urls []URL) ([]byte, error) {
+	return marshal(urlset{Xmlns: xmlns, URLs: entries(urls)})
+}
+
+// Index 生成站点地图索引，sitemaps 为各个站点地图文件的地址
+func Index(sitemaps []URL) ([]byte, error) {
+	return marshal(sitemapIndex{Xmlns: xmlns, Sitemaps: entries(sitemaps)})
+}
+
+func entries(urls []URL) []entry {
+	result := make([]entry, 0, len(urls))
+	for _, u := range urls {
+		e := entry{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			e.LastMod = u.LastMod.Format(time.RFC3339)
+		}
+		result = append(result, e)
+	}
+
+	return result
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+// Robots 生成允许所有爬虫访问 disallow 以外路径的 robots.txt，并声明站点地图的地址
+func Robots(disallow []string, sitemapURL string) []byte {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, path := range disallow {
+		b.WriteString("Disallow: " + path + "\n")
+	}
+	b.WriteString("\nSitemap: " + sitemapURL + "\n")
+
+	return []byte(b.String())
+}
@@ -0,0 +1,66 @@
+package sitemap
+
+import (
+	"testing"
+	"time"
+)
+
+func TestURLSet(t *testing.T) {
+	data, err := URLSet([]URL{
+		{Loc: "http://localhost:8080/"},
+		{Loc: "http://localhost:8080/api/public/articles?tag_id=1&a=<b>", LastMod: time.Date(2023, 3, 4, 5, 6, 7, 0, time.FixedZone("CST", 8*3600))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>http://localhost:8080/</loc>
+  </url>
+  <url>
+    <loc>http://localhost:8080/api/public/articles?tag_id=1&amp;a=&lt;b&gt;</loc>
+    <lastmod>2023-03-04T05:06:07+08:00</lastmod>
+  </url>
+</urlset>`
+	if string(data) != want {
+		t.Errorf("URLSet() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	data, err := Index([]URL{{Loc: "http://localhost:8080/sitemaps/1.xml"}, {Loc: "http://localhost:8080/sitemaps/2.xml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap>
+    <loc>http://localhost:8080/sitemaps/1.xml</loc>
+  </sitemap>
+  <sitemap>
+    <loc>http://localhost:8080/sitemaps/2.xml</loc>
+  </sitemap>
+</sitemapindex>`
+	if string(data) != want {
+		t.Errorf("Index() =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestRobots(t *testing.T) {
+	tests := []struct {
+		disallow []string
+		want     string
+	}{
+		{nil, "User-agent: *\nDisallow:\n\nSitemap: http://localhost:8080/sitemap.xml\n"},
+		{[]string{"/api/v1/", "/auth"}, "User-agent: *\nDisallow: /api/v1/\nDisallow: /auth\n\nSitemap: http://localhost:8080/sitemap.xml\n"},
+	}
+
+	for _, tt := range tests {
+		if got := string(Robots(tt.disallow, "http://localhost:8080/sitemap.xml")); got != tt.want {
+			t.Errorf("Robots(%v) = %q, want %q", tt.disallow, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Local 保存在本地磁盘的 root 目录下，通过 baseURL 下的静态文件服务访问，只适合单实例部署
+type Local struct {
+	root    string
+	baseURL string
+}
+
+func NewLocal(root, baseURL string) *Local {
+	return &Local{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Put 先写入临时文件再重命名，同时写入相同的 key 时不会读取到写了一半的文件
+func (l *Local) Put(key string, r io.Reader, contentType string) error {
+	dst := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	//临时文件以 . 开头，List 时跳过
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), "."+filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	//TempFile 创建的文件权限为 0600，静态文件服务需要其他用户可读
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+func (l *Local) Get(key string) (io.ReadCloser, error) {
+	f, err := os.Open(l.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+
+	return f, err
+}
+
+func (l *Local) Delete(key string) error {
+	if err := os.Remove(l.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (l *Local) Stat(key string) (*Object, error) {
+	info, err := os.Stat(l.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Object{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+func (l *Local) List(prefix string) ([]*Object, error) {
+	//prefix 可以是目录，也可以包含文件名的开头部分，从所在的目录开始遍历
+	dir := prefix
+	if !strings.HasSuffix(dir, "/") {
+		dir = path.Dir(dir)
+	}
+
+	var objects []*Object
+	err := filepath.Walk(l.path(dir), func(src string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.root, src)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, &Object{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + key
+}
+
+// FullPath 返回 key 在本地磁盘上的路径，用于注册静态文件服务
+func (l *Local) FullPath(key string) string {
+	return l.path(key)
+}
+
+// path 以 / 开头的路径 Clean 后不会包含 ../，key 不会指向 root 以外的文件
+func (l *Local) path(key string) string {
+	return filepath.Join(l.root, filepath.FromSlash(path.Clean("/"+key)))
+}
@@ -0,0 +1,297 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3 请求的超时时间
+const s3Timeout = 60 * time.Second
+
+// S3Config S3 兼容存储（AWS S3、MinIO 等）的配置
+type S3Config struct {
+	//如 https://s3.us-east-1.amazonaws.com、http://127.0.0.1:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	//为 true 时使用 Endpoint/Bucket/key 访问，MinIO 需要开启；否则使用 Bucket.Endpoint/key
+	PathStyle bool
+	//文件的访问地址前缀，如 CDN 地址，为空时使用存储桶的地址
+	PublicUrl string
+}
+
+// S3 通过 S3 REST API 读写文件，使用 AWS Signature Version 4 签名，多实例部署时共享同一个存储桶
+type S3 struct {
+	config    S3Config
+	bucketURL *url.URL
+	publicURL string
+	client    *http.Client
+}
+
+func NewS3(config *S3Config) (*S3, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" || config.Bucket == "" {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q or bucket %q", config.Endpoint, config.Bucket)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	bucketURL := *endpoint
+	if config.PathStyle {
+		bucketURL.Path += "/" + config.Bucket
+	} else {
+		bucketURL.Host = config.Bucket + "." + bucketURL.Host
+	}
+
+	publicURL := strings.TrimSuffix(config.PublicUrl, "/")
+	if publicURL == "" {
+		publicURL = bucketURL.String()
+	}
+
+	return &S3{
+		config:    *config,
+		bucketURL: &bucketURL,
+		publicURL: publicURL,
+		client:    &http.Client{Timeout: s3Timeout},
+	}, nil
+}
+
+// Put 需要计算请求体的 SHA-256，文件会先完整读入内存
+func (s *S3) Put(key string, r io.Reader, contentType string) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(http.MethodPut, key, nil, header, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3) Get(key string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil, nil, nil)
+	if err == ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3) Stat(key string) (*Object, error) {
+	resp, err := s.do(http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &Object{Key: key, Size: resp.ContentLength, ModTime: modTime}, nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List 使用 ListObjectsV2，结果超过 1000 个时按 continuation-token 继续读取
+func (s *S3) List(prefix string) ([]*Object, error) {
+	var objects []*Object
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		resp, err := s.do(http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, &Object{Key: content.Key, Size: content.Size, ModTime: content.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (s *S3) URL(key string) string {
+	return s.publicURL + "/" + encodePath(key)
+}
+
+// do 发送签名后的请求，404 时返回 ErrNotExist，其他非 2xx 的响应返回包含响应内容的错误
+func (s *S3) do(method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *s.bucketURL
+	u.RawPath = u.EscapedPath() + "/" + encodePath(key)
+	u.Path = u.Path + "/" + key
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	s.sign(req, body, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotExist
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("storage: s3 %s %s: %s %s", method, key, resp.Status, message)
+	}
+
+	return resp, nil
+}
+
+// sign 按 AWS Signature Version 4 计算签名并设置 Authorization，请求中已有的所有头都参与签名
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *S3) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.config.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalQuery 按参数名排序，签名要求空格编码为 %20 而不是 +
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func encodePath(key string) string {
+	return uriEncode(key, false)
+}
+
+// uriEncode 除 A-Z a-z 0-9 - _ . ~ 以外的字节都编码为 %XX，encodeSlash 为 false 时保留 /
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"gin-blog/pkg/setting"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+var ErrNotExist = errors.New("storage: object does not exist")
+
+// Object 存储中的一个文件
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Storage 保存上传的图片、导出的文件和海报等，key 为以 / 分隔的相对路径，如 upload/images/ab/cd/xxx.jpg。
+// Get、Stat 在文件不存在时返回 ErrNotExist，Delete 删除不存在的文件不返回错误，List 返回 key 以 prefix 开头的所有文件
+type Storage interface {
+	Put(key string, r io.Reader, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	Stat(key string) (*Object, error)
+	List(prefix string) ([]*Object, error)
+	URL(key string) string
+}
+
+// Default 由 Setup 根据配置创建，所有生成文件的地方都通过它读写
+var Default Storage
+
+func Setup() error {
+	s, err := New()
+	if err != nil {
+		return err
+	}
+	Default = s
+
+	return nil
+}
+
+// New 根据配置 [storage] Type 创建存储
+func New() (Storage, error) {
+	switch setting.StorageSetting.Type {
+	case "", "local":
+		return NewLocal(setting.AppSetting.RuntimeRootPath, setting.AppSetting.PrefixUrl), nil
+	case "s3":
+		return NewS3(&S3Config{
+			Endpoint:  setting.StorageSetting.Endpoint,
+			Region:    setting.StorageSetting.Region,
+			Bucket:    setting.StorageSetting.Bucket,
+			AccessKey: setting.StorageSetting.AccessKey,
+			SecretKey: setting.StorageSetting.SecretKey,
+			PathStyle: setting.StorageSetting.PathStyle,
+			PublicUrl: setting.StorageSetting.PublicUrl,
+		})
+	}
+
+	return nil, fmt.Errorf("storage: unknown storage type %q", setting.StorageSetting.Type)
+}
+
+// ReadAll 读取整个文件
+func ReadAll(s Storage, key string) ([]byte, error) {
+	r, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
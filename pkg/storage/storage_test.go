@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testStorage 检查 Storage 接口约定的行为，每种存储都需要通过
+func testStorage(t *testing.T, s Storage) {
+	t.Helper()
+
+	files := map[string]string{
+		"upload/images/ab/cd/a.jpg":   "a",
+		"upload/images/ab/ef/b.png":   "bb",
+		"upload/images/a b/测试.png":    "ccc",
+		"poster/poster-1-old.png":     "dddd",
+		"poster/poster-1-new.png":     "eeeee",
+		"poster/poster-12-other.png":  "ffffff",
+		"export/articles-1-token.csv": "ggggggg",
+	}
+	for key, content := range files {
+		if err := s.Put(key, strings.NewReader(content), "application/octet-stream"); err != nil {
+			t.Fatalf("Put(%q) = %v", key, err)
+		}
+	}
+
+	t.Run("get", func(t *testing.T) {
+		for key, content := range files {
+			r, err := s.Get(key)
+			if err != nil {
+				t.Fatalf("Get(%q) = %v", key, err)
+			}
+			data, err := ioutil.ReadAll(r)
+			r.Close()
+			if err != nil || string(data) != content {
+				t.Errorf("Get(%q) = %q, %v, want %q", key, data, err, content)
+			}
+		}
+		if _, err := s.Get("upload/images/missing.jpg"); err != ErrNotExist {
+			t.Errorf("Get(missing) = %v, want ErrNotExist", err)
+		}
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		object, err := s.Stat("poster/poster-1-new.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if object.Key != "poster/poster-1-new.png" || object.Size != 5 {
+			t.Errorf("Stat() = %+v", object)
+		}
+		if _, err := s.Stat("poster/missing.png"); err != ErrNotExist {
+			t.Errorf("Stat(missing) = %v, want ErrNotExist", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		tests := []struct {
+			prefix string
+			want   []string
+		}{
+			{"upload/images/", []string{"upload/images/a b/测试.png", "upload/images/ab/cd/a.jpg", "upload/images/ab/ef/b.png"}},
+			{"poster/poster-1-", []string{"poster/poster-1-new.png", "poster/poster-1-old.png"}},
+			{"export/", []string{"export/articles-1-token.csv"}},
+			{"missing/", nil},
+		}
+		for _, tt := range tests {
+			objects, err := s.List(tt.prefix)
+			if err != nil {
+				t.Fatalf("List(%q) = %v", tt.prefix, err)
+			}
+			var got []string
+			for _, object := range objects {
+				if object.Size != int64(len(files[object.Key])) {
+					t.Errorf("List(%q) size of %q = %d", tt.prefix, object.Key, object.Size)
+				}
+				got = append(got, object.Key)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("List(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		if err := s.Put("poster/poster-1-new.png", strings.NewReader("new"), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+		object, err := s.Stat("poster/poster-1-new.png")
+		if err != nil || object.Size != 3 {
+			t.Errorf("Stat() after overwrite = %+v, %v", object, err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := s.Delete("poster/poster-1-old.png"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Stat("poster/poster-1-old.png"); err != ErrNotExist {
+			t.Errorf("Stat() after Delete = %v, want ErrNotExist", err)
+		}
+		if err := s.Delete("poster/missing.png"); err != nil {
+			t.Errorf("Delete(missing) = %v, want nil", err)
+		}
+	})
+}
+
+func TestLocal(t *testing.T) {
+	root := t.TempDir()
+	s := NewLocal(root, "http://localhost:8080/")
+	testStorage(t, s)
+
+	if got := s.URL("poster/a.png"); got != "http://localhost:8080/poster/a.png" {
+		t.Errorf("URL() = %q", got)
+	}
+	//key 中的 ../ 不能指向 root 以外的文件
+	if got := s.FullPath("../../etc/passwd"); !strings.HasPrefix(got, root) {
+		t.Errorf("FullPath() = %q, want under %q", got, root)
+	}
+}
@@ -1,30 +1,68 @@
 package upload
 
 import (
-	"fmt"
+	"bytes"
+	"errors"
 	"gin-blog/pkg/file"
-	"gin-blog/pkg/logging"
 	"gin-blog/pkg/setting"
-	"gin-blog/pkg/util"
-	"log"
-	"mime/multipart"
-	"os"
-	"path"
+	"gin-blog/pkg/storage"
+	_ "golang.org/x/image/webp"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
 	"strings"
 )
 
+var (
+	// ErrImageContent 图片内容与扩展名不符，或者无法解析出图片
+	ErrImageContent = errors.New("upload: image content does not match its extension")
+	// ErrImageDimensions 图片宽度或高度超过 ImageMaxWidth、ImageMaxHeight
+	ErrImageDimensions = errors.New("upload: image dimensions exceed the limit")
+)
+
+// 扩展名对应的图片类型，上传的图片内容必须与扩展名一致
+var imageTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// 保存图片时按图片类型使用的扩展名
+var typeExts = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// 文件头中出现这些标签时，浏览器可能把文件当作 HTML 解析，例如 GIF 与 HTML 的多语言文件
+var htmlMarkers = [][]byte{
+	[]byte("<!doctype"),
+	[]byte("<html"),
+	[]byte("<head"),
+	[]byte("<body"),
+	[]byte("<script"),
+	[]byte("<iframe"),
+}
+
 //获取图片完整访问URL
 func GetImageFullUrl(name string) string {
-	return setting.AppSetting.PrefixUrl + "/" + GetImagePath() + name
+	return storage.Default.URL(GetImagePath() + name)
 }
 
-//获取图片名称
-func GetImageName(name string) string {
-	ext := path.Ext(name)
-	fileName := strings.TrimSuffix(name, ext)
-	fileName = util.Md5(fileName)
+//获取图片名称，hash 为图片内容的 SHA-256，按哈希的前两级分目录保存，避免单个目录下的文件过多
+func GetImageName(hash, ext string) string {
+	return hash[:2] + "/" + hash[2:4] + "/" + hash + ext
+}
 
-	return fileName + ext
+//按图片内容获取保存使用的扩展名，不使用上传时的文件名
+func GetImageExt(data []byte) string {
+	return typeExts[http.DetectContentType(data)]
 }
 
 //获取图片路径
@@ -32,11 +70,6 @@ func GetImagePath() string {
 	return setting.AppSetting.ImageSavePath
 }
 
-//获取图片完整路径
-func GetImageFullPath() string {
-	return setting.AppSetting.RuntimeRootPath + GetImagePath()
-}
-
 //检查图片后缀
 func CheckImageExt(fileName string) bool {
 	ext := file.GetExt(fileName)
@@ -49,34 +82,55 @@ func CheckImageExt(fileName string) bool {
 	return false
 }
 
-//检查图片大小
-func CheckImageSize(f multipart.File) bool {
-	size, err := file.GetSize(f)
-	if err != nil {
-		log.Println(err)
-		logging.Warn(err)
-		return false
-	}
-
-	return size <= setting.AppSetting.ImageMaxSize
+//检查图片大小，size 使用 multipart.FileHeader.Size，不需要读取文件内容
+func CheckImageSize(size int64) bool {
+	return size <= int64(setting.AppSetting.ImageMaxSize)
 }
 
-//检查图片
-func CheckImage(src string) error {
-	dir, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("os.Getwd err: %v", err)
+// CheckImageContent 按文件头识别图片的真实类型并解析图片尺寸，拒绝内容与扩展名不符、
+// 文件头包含 HTML、无法解析或尺寸超过限制的图片。只解析图片头，不会解码整张图片，检查完成后读取位置回到文件开头
+func CheckImageContent(f io.ReadSeeker, fileName string) error {
+	want, ok := imageTypes[strings.ToLower(file.GetExt(fileName))]
+	if !ok {
+		return ErrImageContent
+	}
+
+	//http.DetectContentType 最多使用前 512 字节
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err == io.EOF {
+		return ErrImageContent
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if http.DetectContentType(head[:n]) != want || containsHTML(head[:n]) {
+		return ErrImageContent
 	}
 
-	err = file.IsNotExistMkDir(dir + "/" + src)
-	if err != nil {
-		return fmt.Errorf("file.IsNotExistMkDir err: %v", err)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	config, format, err := image.DecodeConfig(f)
+	if err != nil || "image/"+format != want || config.Width <= 0 || config.Height <= 0 {
+		return ErrImageContent
 	}
+	if config.Width > setting.AppSetting.ImageMaxWidth || config.Height > setting.AppSetting.ImageMaxHeight {
+		return ErrImageDimensions
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	return err
+}
 
-	perm := file.CheckPermission(src)
-	if perm == true {
-		return fmt.Errorf("file.CheckPermission Permission denied src: %s", src)
+//文件头中是否包含 HTML 标签
+func containsHTML(head []byte) bool {
+	head = bytes.ToLower(head)
+	for _, marker := range htmlMarkers {
+		if bytes.Contains(head, marker) {
+			return true
+		}
 	}
 
-	return nil
+	return false
 }
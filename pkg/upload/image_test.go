@@ -0,0 +1,109 @@
+package upload
+
+import (
+	"bytes"
+	"gin-blog/pkg/setting"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+)
+
+func setupImageTest(t *testing.T) {
+	t.Helper()
+
+	app := *setting.AppSetting
+	t.Cleanup(func() { *setting.AppSetting = app })
+	setting.AppSetting.ImageMaxWidth = 100
+	setting.AppSetting.ImageMaxHeight = 50
+}
+
+func encodeImage(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			//像素值不规则，避免压缩后的文件过小
+			img.Set(x, y, color.RGBA{uint8(x * y * 7), uint8(x*13 + y*y), uint8(x ^ y), 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestCheckImageContent(t *testing.T) {
+	setupImageTest(t)
+
+	pngData := encodeImage(t, "png", 10, 10)
+	jpegData := encodeImage(t, "jpeg", 64, 48)
+	//足够大的图片，文件头超过 512 字节
+	largePNG := encodeImage(t, "png", 100, 50)
+	//GIF 文件头后紧跟 HTML，声明的尺寸为 1x1
+	polyglot := append([]byte("GIF89a\x01\x00\x01\x00\x00\x00\x00"), "<html><script>alert(1)</script></html>"...)
+
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+		want     error
+	}{
+		{"png", "a.png", pngData, nil},
+		{"upper case ext", "a.PNG", pngData, nil},
+		{"jpeg", "a.jpeg", jpegData, nil},
+		{"gif", "a.gif", encodeImage(t, "gif", 8, 8), nil},
+		{"max dimensions", "a.png", largePNG, nil},
+		{"png named jpg", "a.jpg", pngData, ErrImageContent},
+		{"jpeg named png", "a.png", jpegData, ErrImageContent},
+		{"unknown ext", "a.bmp", pngData, ErrImageContent},
+		{"html named gif", "a.gif", []byte("<html><script>alert(1)</script></html>"), ErrImageContent},
+		{"html gif polyglot", "a.gif", polyglot, ErrImageContent},
+		{"truncated header", "a.png", pngData[:12], ErrImageContent},
+		{"signature only", "a.gif", []byte("GIF89a"), ErrImageContent},
+		{"empty", "a.png", nil, ErrImageContent},
+		{"too wide", "a.png", encodeImage(t, "png", 101, 10), ErrImageDimensions},
+		{"too high", "a.jpg", encodeImage(t, "jpeg", 10, 51), ErrImageDimensions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bytes.NewReader(tt.data)
+			if err := CheckImageContent(r, tt.fileName); err != tt.want {
+				t.Fatalf("CheckImageContent() = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				return
+			}
+
+			//检查通过后从头读取的内容应与原文件一致
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Errorf("reader was not rewound, read %d of %d bytes", len(got), len(tt.data))
+			}
+		})
+	}
+
+	if len(pngData) >= 512 || len(largePNG) <= 512 {
+		t.Errorf("png sizes = %d, %d, want one below and one above 512 bytes", len(pngData), len(largePNG))
+	}
+}
@@ -0,0 +1,191 @@
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"gin-blog/pkg/imaging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"image"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// 缩放版本的扩展名和文件类型
+var (
+	variantExts = map[string]string{
+		imaging.FormatJPEG: ".jpg",
+		imaging.FormatPNG:  ".png",
+	}
+	variantTypes = map[string]string{
+		imaging.FormatJPEG: "image/jpeg",
+		imaging.FormatPNG:  "image/png",
+	}
+)
+
+// Variant 上传图片的一个缩放版本
+type Variant struct {
+	Name    string `json:"name"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Url     string `json:"url"`
+	SaveUrl string `json:"save_url"`
+}
+
+type variantSize struct {
+	name  string
+	width int
+}
+
+// Image 保存后的图片，Width、Height 为按 EXIF 方向摆正后的尺寸
+type Image struct {
+	Width    int
+	Height   int
+	Variants []*Variant
+}
+
+// Setup 检查 [image] 配置，Variants 格式错误或 Format 不是 jpeg、png 时返回错误，
+// 避免服务启动后每次上传图片才失败
+func Setup() error {
+	if _, err := parseVariants(setting.ImageSetting.Variants); err != nil {
+		return err
+	}
+	if format := setting.ImageSetting.Format; format != "" {
+		if _, ok := variantExts[format]; !ok {
+			return fmt.Errorf("upload: unsupported image variant format %q, only jpeg and png can be written", format)
+		}
+	}
+	if quality := setting.ImageSetting.Quality; quality < 0 || quality > 100 {
+		return fmt.Errorf("upload: invalid image quality %d", quality)
+	}
+
+	return nil
+}
+
+// SaveImage 将图片保存为 name，并生成 [image] Variants 中配置的缩放版本。
+// 缩放版本按 EXIF 方向摆正后重新编码，不包含任何元数据
+func SaveImage(data []byte, name string) (*Image, error) {
+	sizes, err := parseVariants(setting.ImageSetting.Variants)
+	if err != nil {
+		return nil, err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	output, err := variantFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := storage.Default.Put(GetImagePath()+name, bytes.NewReader(data), http.DetectContentType(data)); err != nil {
+		return nil, err
+	}
+
+	orientation := imaging.Orientation(data)
+	width, height := imaging.Size(img, orientation)
+	result := &Image{Width: width, Height: height, Variants: make([]*Variant, 0, len(sizes))}
+	for _, size := range sizes {
+		if size.width >= width {
+			continue
+		}
+
+		thumb := imaging.Thumbnail(img, orientation, size.width, output == imaging.FormatJPEG)
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, thumb, output, setting.ImageSetting.Quality); err != nil {
+			return nil, err
+		}
+
+		fileName := variantName(name, size.name, output)
+		if err := storage.Default.Put(GetImagePath()+fileName, &buf, variantTypes[output]); err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, newVariant(size.name, fileName, thumb.Bounds().Dx(), thumb.Bounds().Dy()))
+	}
+
+	return result, nil
+}
+
+// GetImageVariants 获取已经保存的图片的缩放版本，只返回当前配置中存在的版本
+func GetImageVariants(name string) ([]*Variant, error) {
+	sizes, err := parseVariants(setting.ImageSetting.Variants)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make([]*Variant, 0, len(sizes))
+	for _, size := range sizes {
+		for _, format := range []string{imaging.FormatJPEG, imaging.FormatPNG} {
+			fileName := variantName(name, size.name, format)
+			config, err := decodeConfig(GetImagePath() + fileName)
+			if err == storage.ErrNotExist {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, newVariant(size.name, fileName, config.Width, config.Height))
+		}
+	}
+
+	return variants, nil
+}
+
+func variantName(name, variant, format string) string {
+	return strings.TrimSuffix(name, path.Ext(name)) + "_" + variant + variantExts[format]
+}
+
+func newVariant(variant, fileName string, width, height int) *Variant {
+	return &Variant{
+		Name:    variant,
+		Width:   width,
+		Height:  height,
+		Url:     GetImageFullUrl(fileName),
+		SaveUrl: GetImagePath() + fileName,
+	}
+}
+
+func decodeConfig(key string) (image.Config, error) {
+	r, err := storage.Default.Get(key)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer r.Close()
+
+	config, _, err := image.DecodeConfig(r)
+	return config, err
+}
+
+// 解析 名称:宽度 格式的缩放版本配置
+func parseVariants(values []string) ([]variantSize, error) {
+	sizes := make([]variantSize, 0, len(values))
+	for _, value := range values {
+		name, width, _ := strings.Cut(strings.TrimSpace(value), ":")
+		w, err := strconv.Atoi(width)
+		if name == "" || strings.ContainsAny(name, "/\\.") || err != nil || w <= 0 {
+			return nil, fmt.Errorf("upload: invalid image variant %q", value)
+		}
+		sizes = append(sizes, variantSize{name: name, width: w})
+	}
+
+	return sizes, nil
+}
+
+// 没有配置 Format 时 JPEG 原图仍输出 JPEG，其他格式可能带有透明通道，输出 PNG
+func variantFormat(source string) (string, error) {
+	format := setting.ImageSetting.Format
+	if format == "" {
+		if source == "jpeg" {
+			return imaging.FormatJPEG, nil
+		}
+		return imaging.FormatPNG, nil
+	}
+	if _, ok := variantExts[format]; !ok {
+		return "", fmt.Errorf("upload: unsupported image variant format %q", format)
+	}
+
+	return format, nil
+}
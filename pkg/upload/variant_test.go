@@ -0,0 +1,196 @@
+package upload
+
+import (
+	"bytes"
+	"gin-blog/pkg/imaging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"image"
+	"io"
+	"strings"
+	"testing"
+)
+
+func setupVariantTest(t *testing.T, variants []string, format string) {
+	t.Helper()
+
+	app, img := *setting.AppSetting, *setting.ImageSetting
+	t.Cleanup(func() {
+		*setting.AppSetting = app
+		*setting.ImageSetting = img
+	})
+	setting.AppSetting.ImageSavePath = "upload/images/"
+	setting.ImageSetting.Variants = variants
+	setting.ImageSetting.Format = format
+	setting.ImageSetting.Quality = 80
+
+	def := storage.Default
+	t.Cleanup(func() { storage.Default = def })
+	storage.Default = storage.NewLocal(t.TempDir(), "http://localhost:8080")
+}
+
+func storedConfig(t *testing.T, key string) (image.Config, string) {
+	t.Helper()
+
+	r, err := storage.Default.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	config, format, err := image.DecodeConfig(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return config, format
+}
+
+func TestParseVariants(t *testing.T) {
+	got, err := parseVariants([]string{"thumb:200", " medium:800 "})
+	if err != nil || len(got) != 2 || got[0] != (variantSize{"thumb", 200}) || got[1] != (variantSize{"medium", 800}) {
+		t.Errorf("parseVariants() = %v, %v", got, err)
+	}
+	if got, err := parseVariants(nil); err != nil || len(got) != 0 {
+		t.Errorf("parseVariants(nil) = %v, %v", got, err)
+	}
+
+	for _, value := range []string{"", "thumb", "thumb:", ":200", "thumb:abc", "thumb:0", "thumb:-1", "a/b:200", "a\\b:200", "a.b:200"} {
+		if _, err := parseVariants([]string{"large:1600", value}); err == nil {
+			t.Errorf("parseVariants(%q) = nil error", value)
+		}
+	}
+}
+
+func TestSaveImage(t *testing.T) {
+	setupVariantTest(t, []string{"small:50", "same:200", "large:400"}, "")
+
+	//方向为 6 的 JPEG，保存时宽高互换
+	data := exifJPEGData(t, 200, 100, 6)
+	img, err := SaveImage(data, "ab/cd/abcd.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 100 || img.Height != 200 {
+		t.Errorf("size = %dx%d, want 100x200", img.Width, img.Height)
+	}
+
+	//摆正后宽 100，same 和 large 不小于原图宽度，不生成
+	if len(img.Variants) != 1 {
+		t.Fatalf("variants = %+v, want only small", img.Variants)
+	}
+	v := img.Variants[0]
+	if v.Name != "small" || v.Width != 50 || v.Height != 100 || v.SaveUrl != "upload/images/ab/cd/abcd_small.jpg" ||
+		v.Url != "http://localhost:8080/upload/images/ab/cd/abcd_small.jpg" {
+		t.Errorf("variant = %+v", v)
+	}
+	if config, format := storedConfig(t, v.SaveUrl); format != "jpeg" || config.Width != 50 || config.Height != 100 {
+		t.Errorf("saved variant = %s %dx%d", format, config.Width, config.Height)
+	}
+
+	//原图原样保存
+	r, err := storage.Default.Get("upload/images/ab/cd/abcd.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if saved, _ := io.ReadAll(r); !bytes.Equal(saved, data) {
+		t.Error("original image was changed")
+	}
+
+	variants, err := GetImageVariants("ab/cd/abcd.jpg")
+	if err != nil || len(variants) != 1 || *variants[0] != *v {
+		t.Errorf("GetImageVariants() = %v, %v", variants, err)
+	}
+}
+
+func TestSaveImageFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		source string
+		want   string
+	}{
+		{"", "png", "png"},
+		{"", "jpeg", "jpeg"},
+		{imaging.FormatJPEG, "png", "jpeg"},
+		{imaging.FormatPNG, "jpeg", "png"},
+	}
+
+	for _, tt := range tests {
+		setupVariantTest(t, []string{"small:10"}, tt.format)
+
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 20, 20)), tt.source, 0); err != nil {
+			t.Fatal(err)
+		}
+		img, err := SaveImage(buf.Bytes(), "a.img")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, format := storedConfig(t, img.Variants[0].SaveUrl); format != tt.want || !strings.HasSuffix(img.Variants[0].SaveUrl, variantExts[tt.want]) {
+			t.Errorf("Format %q, source %s: variant %s saved as %s, want %s", tt.format, tt.source, img.Variants[0].SaveUrl, format, tt.want)
+		}
+	}
+}
+
+func TestSaveImageInvalidConfig(t *testing.T) {
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 20, 20)), imaging.FormatPNG, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	setupVariantTest(t, []string{"small"}, "")
+	if _, err := SaveImage(buf.Bytes(), "a.png"); err == nil {
+		t.Error("SaveImage() with invalid Variants = nil error")
+	}
+
+	setupVariantTest(t, []string{"small:10"}, "webp")
+	if _, err := SaveImage(buf.Bytes(), "a.png"); err == nil {
+		t.Error("SaveImage() with Format webp = nil error")
+	}
+}
+
+// exifJPEGData 创建 width x height、EXIF 方向为 orientation 的 JPEG
+func exifJPEGData(t *testing.T, width, height, orientation int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height)), imaging.FormatJPEG, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	//小端 TIFF，IFD0 只有方向一个条目
+	tiff := []byte("II\x2a\x00\x08\x00\x00\x00\x01\x00\x12\x01\x03\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	tiff[18] = byte(orientation)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xff, 0xd8, 0xff, 0xe1, 0, byte(len(segment) + 2)}
+	data = append(data, segment...)
+
+	return append(data, buf.Bytes()[2:]...)
+}
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		variants []string
+		format   string
+		quality  int
+		wantErr  bool
+	}{
+		{[]string{"thumb:200", "large:1600"}, "", 82, false},
+		{nil, imaging.FormatJPEG, 0, false},
+		{[]string{"thumb:200"}, imaging.FormatPNG, 100, false},
+		{[]string{"thumb:200"}, "webp", 82, true},
+		{[]string{"thumb:200"}, "JPEG", 82, true},
+		{[]string{"thumb"}, "", 82, true},
+		{[]string{"thumb:200"}, "", 101, true},
+		{[]string{"thumb:200"}, "", -1, true},
+	}
+
+	for _, tt := range tests {
+		setupVariantTest(t, tt.variants, tt.format)
+		setting.ImageSetting.Quality = tt.quality
+		if err := Setup(); (err != nil) != tt.wantErr {
+			t.Errorf("Setup(%v, %q, %d) = %v, want error %v", tt.variants, tt.format, tt.quality, err, tt.wantErr)
+		}
+	}
+}
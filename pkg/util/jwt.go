@@ -1,41 +1,51 @@
 package util
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"gin-blog/pkg/setting"
 	"github.com/dgrijalva/jwt-go"
 	"time"
 )
 
-var jwtSecret = []byte(setting.AppSetting.JwtSecret)
-
 type Claims struct {
 	Username string `json:"username"`
-	Password string `json:"password"`
+	Role     string `json:"role"`
 	jwt.StandardClaims
 }
 
-func GenerateToken(username, password string) (string, error) {
+func GenerateToken(username, role string) (string, *Claims, error) {
 	nowTime := time.Now()
-	expireTime := nowTime.Add(3 * time.Hour)
+	expireTime := nowTime.Add(setting.AppSetting.JwtExpire)
+
+	id, err := RandomToken(16)
+	if err != nil {
+		return "", nil, err
+	}
 
-	claims := Claims{
+	claims := &Claims{
 		username,
-		password,
+		role,
 		jwt.StandardClaims{
+			Id:        id,
+			IssuedAt:  nowTime.Unix(),
 			ExpiresAt: expireTime.Unix(),
 			Issuer:    "gin-blog",
 		},
 	}
 
 	tokenClaims := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err := tokenClaims.SignedString(jwtSecret)
+	token, err := tokenClaims.SignedString(jwtSecret())
 
-	return token, err
+	return token, claims, err
 }
 
 func ParseToken(token string) (*Claims, error) {
 	tokenClaims, err := jwt.ParseWithClaims(token, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return jwtSecret(), nil
 	})
 
 	if tokenClaims != nil {
@@ -46,3 +56,18 @@ func ParseToken(token string) (*Claims, error) {
 
 	return nil, err
 }
+
+// RandomToken 生成 n 字节的随机数并以十六进制字符串返回
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// 密钥需要在 setting.Setup() 之后读取，不能在包初始化时缓存
+func jwtSecret() []byte {
+	return []byte(setting.AppSetting.JwtSecret)
+}
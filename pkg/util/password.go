@@ -0,0 +1,32 @@
+package util
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPassword 使用 bcrypt 生成带盐的密码哈希
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// CheckPassword 校验密码，needRehash 为 true 表示哈希是旧版的 MD5，校验通过后应尽快升级为 bcrypt
+func CheckPassword(hash, password string) (ok bool, needRehash bool) {
+	if strings.HasPrefix(hash, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil, false
+	}
+
+	if len(hash) == 32 {
+		ok = subtle.ConstantTimeCompare([]byte(strings.ToLower(hash)), []byte(Md5(password))) == 1
+		return ok, ok
+	}
+
+	return false, false
+}
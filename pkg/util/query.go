@@ -0,0 +1,22 @@
+package util
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"strings"
+)
+
+// GetIntList 读取以逗号分隔的整数列表，如 tag_ids=1,2,3，无法解析的值为 0
+func GetIntList(c *gin.Context, key string) []int {
+	arg := c.Query(key)
+	if arg == "" {
+		return nil
+	}
+
+	var result []int
+	for _, v := range strings.Split(arg, ",") {
+		result = append(result, com.StrTo(strings.TrimSpace(v)).MustInt())
+	}
+
+	return result
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"gin-blog/pkg/setting"
+	"strconv"
+	"strings"
+)
+
+// AbsoluteURL 将站内路径转换为以 PrefixUrl 开头的绝对地址，已经是绝对地址时原样返回
+func AbsoluteURL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+
+	return strings.TrimSuffix(setting.AppSetting.PrefixUrl, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// 没有配置 [site] ArticlePath、TagPath 时使用公开接口的地址
+const (
+	defaultArticlePath = "/api/public/articles/{id}"
+	defaultTagPath     = "/api/public/articles?tag_id={id}"
+)
+
+// ArticleURL 文章页面的绝对地址，路径由 [site] ArticlePath 配置
+func ArticleURL(id int) string {
+	return AbsoluteURL(pagePath(setting.SiteSetting.ArticlePath, defaultArticlePath, id))
+}
+
+// TagURL 标签页面的绝对地址，路径由 [site] TagPath 配置
+func TagURL(id int) string {
+	return AbsoluteURL(pagePath(setting.SiteSetting.TagPath, defaultTagPath, id))
+}
+
+func pagePath(path, defaultPath string, id int) string {
+	if path == "" {
+		path = defaultPath
+	}
+
+	return strings.ReplaceAll(path, "{id}", strconv.Itoa(id))
+}
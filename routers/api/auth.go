@@ -1,19 +1,19 @@
 package api
 
 import (
-	"gin-blog/models"
+	"gin-blog/middleware/jwt"
 	"gin-blog/pkg/app"
 	"gin-blog/pkg/err"
-	"gin-blog/pkg/util"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/rbac"
 	"gin-blog/service/auth_service"
-	"github.com/astaxie/beego/validation"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
-type auth struct {
-	Username string `valid:"Required; MaxSize(50)"`
-	Password string `valid:"Required; MaxSize(50)"`
+type AuthForm struct {
+	Username string `form:"username" valid:"Required; MaxSize(50)"`
+	Password string `form:"password" valid:"Required; MaxSize(72)"`
 }
 
 // @Summary Get Auth
@@ -24,26 +24,20 @@ type auth struct {
 // @Failure 500 {object} app.Response
 // @Router /auth [post]
 func GetAuth(c *gin.Context) {
-	appG := app.Gin{C: c}
-	valid := validation.Validation{}
-
-	var user models.Auth
-	c.ShouldBind(&user)
-	username := user.Username
-	password := util.Md5(user.Password)
+	var (
+		appG = app.Gin{C: c}
+		form AuthForm
+	)
 
-	a := auth{Username: username, Password: password}
-	ok, _ := valid.Valid(&a)
-
-	if !ok {
-		app.MarkErrors(valid.Errors)
-		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+	httpCode, errCode := app.BindAndValid(c, &form)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
 		return
 	}
 
 	authService := auth_service.Auth{
-		Username: username,
-		Password: password,
+		Username: form.Username,
+		Password: form.Password,
 	}
 	isExist, e := authService.Check()
 	if e != nil {
@@ -56,13 +50,119 @@ func GetAuth(c *gin.Context) {
 		return
 	}
 
-	token, e := util.GenerateToken(username, password)
+	user, e := authService.Get()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_USER_FAIL, nil)
+		return
+	}
+
+	token, e := auth_service.IssueToken(user)
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_AUTH_TOKEN, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, token)
+}
+
+type RefreshForm struct {
+	RefreshToken string `form:"refresh_token" valid:"Required;MaxSize(100)"`
+}
+
+// @Summary Exchange a refresh token for a new token pair
+// @Produce  json
+// @Param refresh_token body string true "RefreshToken"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /auth/refresh [post]
+func RefreshAuth(c *gin.Context) {
+	var (
+		appG = app.Gin{C: c}
+		form RefreshForm
+	)
+
+	httpCode, errCode := app.BindAndValid(c, &form)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	token, e := auth_service.RefreshToken(form.RefreshToken)
+	if e == auth_service.ErrInvalidRefreshToken {
+		appG.Response(http.StatusUnauthorized, err.ERROR_AUTH_REFRESH_TOKEN, nil)
+		return
+	}
 	if e != nil {
+		logging.Warn(e)
 		appG.Response(http.StatusInternalServerError, err.ERROR_AUTH_TOKEN, nil)
 		return
 	}
 
-	appG.Response(http.StatusOK, err.SUCCESS, map[string]string{
-		"token": token,
-	})
+	appG.Response(http.StatusOK, err.SUCCESS, token)
+}
+
+// @Summary Revoke the current token and its refresh token
+// @Produce  json
+// @Param refresh_token body string false "RefreshToken"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /auth/logout [post]
+func Logout(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	claims := jwt.GetClaims(c)
+	if claims == nil {
+		appG.Response(http.StatusUnauthorized, err.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	if e := auth_service.RevokeToken(claims, c.PostForm("refresh_token")); e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_AUTH_LOGOUT_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+type SetupForm struct {
+	Username string `form:"username" valid:"Required;MaxSize(50)"`
+	Password string `form:"password" valid:"Required;MinSize(6);MaxSize(72)"`
+}
+
+// @Summary Create the first user, who is always an admin
+// @Produce  json
+// @Param username body string true "username"
+// @Param password body string true "password"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /auth/setup [post]
+func Setup(c *gin.Context) {
+	var (
+		appG = app.Gin{C: c}
+		form SetupForm
+	)
+
+	httpCode, errCode := app.BindAndValid(c, &form)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	authService := auth_service.Auth{
+		Username: form.Username,
+		Password: form.Password,
+		Role:     rbac.RoleAdmin,
+	}
+	added, e := authService.AddFirst()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_ADD_USER_FAIL, nil)
+		return
+	}
+	if !added {
+		appG.Response(http.StatusForbidden, err.ERROR_USER_ALREADY_SETUP, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
 }
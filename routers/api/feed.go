@@ -0,0 +1,78 @@
+package api
+
+import (
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/logging"
+	"gin-blog/service/article_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"net/http"
+)
+
+// @Summary RSS 2.0 feed of the latest published articles
+// @Produce  xml
+// @Param id path int false "TagID, only for /tags/{id}/feed.xml"
+// @Success 200 {string} string
+// @Failure 404 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /feed.xml [get]
+func GetRSS(c *gin.Context) {
+	feed(c, article_service.FeedRSS, "application/rss+xml; charset=utf-8")
+}
+
+// @Summary Atom 1.0 feed of the latest published articles
+// @Produce  xml
+// @Param id path int false "TagID, only for /tags/{id}/atom.xml"
+// @Success 200 {string} string
+// @Failure 404 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /atom.xml [get]
+func GetAtom(c *gin.Context) {
+	feed(c, article_service.FeedAtom, "application/atom+xml; charset=utf-8")
+}
+
+// @Summary JSON Feed 1.1 of the latest published articles
+// @Produce  json
+// @Param id path int false "TagID, only for /tags/{id}/feed.json"
+// @Success 200 {string} string
+// @Failure 404 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /feed.json [get]
+func GetJSONFeed(c *gin.Context) {
+	feed(c, article_service.FeedJSON, "application/feed+json; charset=utf-8")
+}
+
+// feed 站点订阅和标签订阅共用，标签订阅的路由中带有标签ID
+func feed(c *gin.Context, format, contentType string) {
+	appG := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	var tagId int
+	if arg := c.Param("id"); arg != "" {
+		tagId = com.StrTo(arg).MustInt()
+		valid.Min(tagId, 1, "id").Message("标签ID必须大于0")
+	}
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	feedService := article_service.Feed{Format: format, TagID: tagId}
+	data, e := feedService.Get()
+	if e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_GEN_FEED_FAIL, nil)
+		return
+	}
+	//标签不存在时返回 404，HTTPCache 不会缓存非 200 的响应
+	if data == nil {
+		appG.Response(http.StatusNotFound, err.ERROR_NOT_EXIST_TAG, nil)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
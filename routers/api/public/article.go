@@ -0,0 +1,96 @@
+package public
+
+import (
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/article_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"net/http"
+)
+
+// @Summary Get a single published article
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/public/articles/{id} [get]
+func GetArticle(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{ID: id, PublishedOnly: true}
+	article, e := articleService.Get()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_ARTICLE_FAIL, nil)
+		return
+	}
+	if article == nil {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_ARTICLE, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, newArticle(article, true))
+}
+
+// @Summary Get published articles
+// @Produce  json
+// @Param tag_id query int false "TagID"
+// @Param tag_ids query string false "TagIDs, comma separated"
+// @Param tag_match query string false "any or all"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/public/articles [get]
+func GetArticles(c *gin.Context) {
+	appG := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	tagIds, matchAll := app.TagFilters(c, &valid)
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{
+		TagIDs:        tagIds,
+		MatchAllTags:  matchAll,
+		State:         -1,
+		PublishedOnly: true,
+		PageNum:       util.GetPage(c),
+		PageSize:      setting.AppSetting.PageSize,
+	}
+
+	total, e := articleService.Count()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_COUNT_ARTICLE_FAIL, nil)
+		return
+	}
+
+	articles, e := articleService.GetAll()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_ARTICLES_FAIL, nil)
+		return
+	}
+
+	lists := make([]Article, 0, len(articles))
+	for _, article := range articles {
+		lists = append(lists, newArticle(article, false))
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": lists,
+		"total": total,
+	})
+}
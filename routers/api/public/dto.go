@@ -0,0 +1,58 @@
+package public
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/markdown"
+)
+
+// Article 公开接口返回的文章，不包含创建人、修改人等管理信息
+type Article struct {
+	ID            int                 `json:"id"`
+	Title         string              `json:"title"`
+	Desc          string              `json:"desc"`
+	Content       string              `json:"content,omitempty"`
+	ContentHtml   string              `json:"content_html,omitempty"`
+	Toc           []*markdown.TocItem `json:"toc,omitempty"`
+	CoverImageUrl string              `json:"cover_image_url"`
+	Tags          []Tag               `json:"tags"`
+	CreatedOn     int                 `json:"created_on"`
+	ModifiedOn    int                 `json:"modified_on"`
+}
+
+// Tag 公开接口返回的标签
+type Tag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// newArticle 列表中不返回正文，withContent 为 true 时返回正文及渲染结果
+func newArticle(a *models.Article, withContent bool) Article {
+	article := Article{
+		ID:            a.ID,
+		Title:         a.Title,
+		Desc:          a.Desc,
+		CoverImageUrl: a.CoverImageUrl,
+		Tags:          newTags(a.Tags),
+		CreatedOn:     a.CreatedOn,
+		ModifiedOn:    a.ModifiedOn,
+	}
+	if withContent {
+		article.Content = a.Content
+		article.ContentHtml = a.ContentHtml
+		article.Toc = a.Toc
+	}
+
+	return article
+}
+
+// newTags 只返回启用的标签
+func newTags(tags []models.Tag) []Tag {
+	result := make([]Tag, 0, len(tags))
+	for _, t := range tags {
+		if t.State == 1 {
+			result = append(result, Tag{ID: t.ID, Name: t.Name})
+		}
+	}
+
+	return result
+}
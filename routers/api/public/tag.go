@@ -0,0 +1,42 @@
+package public
+
+import (
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/tag_service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// @Summary Get enabled tags
+// @Produce  json
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/public/tags [get]
+func GetTags(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	tagService := tag_service.Tag{
+		State:    1,
+		PageNum:  util.GetPage(c),
+		PageSize: setting.AppSetting.PageSize,
+	}
+	tags, e := tagService.GetAll()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_TAGS_FAIL, nil)
+		return
+	}
+
+	count, e := tagService.Count()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_COUNT_TAG_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": newTags(tags),
+		"total": count,
+	})
+}
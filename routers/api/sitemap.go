@@ -0,0 +1,80 @@
+package api
+
+import (
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/sitemap"
+	"gin-blog/pkg/util"
+	"gin-blog/service/article_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var sitemapPageName = regexp.MustCompile(`^[0-9]+\.xml$`)
+
+// @Summary Sitemap of published articles and enabled tags, or a sitemap index when there are too many URLs
+// @Produce  xml
+// @Success 200 {string} string
+// @Failure 500 {object} app.Response
+// @Router /sitemap.xml [get]
+func GetSitemap(c *gin.Context) {
+	getSitemap(c, 0)
+}
+
+// @Summary A page of the sitemap listed in the sitemap index
+// @Produce  xml
+// @Param page path string true "Page, e.g. 1.xml"
+// @Success 200 {string} string
+// @Failure 404 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /sitemaps/{page} [get]
+func GetSitemapPage(c *gin.Context) {
+	appG := app.Gin{C: c}
+	name := c.Param("page")
+	page := com.StrTo(strings.TrimSuffix(name, ".xml")).MustInt()
+	valid := validation.Validation{}
+	valid.Match(name, sitemapPageName, "page").Message("站点地图名称格式不正确")
+	valid.Min(page, 1, "page").Message("页码必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	getSitemap(c, page)
+}
+
+// @Summary robots.txt
+// @Produce  plain
+// @Success 200 {string} string
+// @Router /robots.txt [get]
+func GetRobots(c *gin.Context) {
+	data := sitemap.Robots(setting.SiteSetting.RobotsDisallow, util.AbsoluteURL("/sitemap.xml"))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", data)
+}
+
+func getSitemap(c *gin.Context, page int) {
+	appG := app.Gin{C: c}
+
+	sitemapService := article_service.Sitemap{Page: page}
+	data, e := sitemapService.Get()
+	if e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_GEN_SITEMAP_FAIL, nil)
+		return
+	}
+	//不存在的页返回 404，HTTPCache 不会缓存非 200 的响应
+	if data == nil {
+		appG.Response(http.StatusNotFound, err.ERROR_NOT_EXIST_SITEMAP, nil)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
@@ -1,14 +1,21 @@
 package api
 
 import (
+	"errors"
+	"gin-blog/middleware/jwt"
 	"gin-blog/pkg/app"
 	"gin-blog/pkg/err"
 	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
 	"gin-blog/pkg/upload"
+	"gin-blog/service/media_service"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
+// 请求体中除图片外 multipart 边界、表单字段等内容允许占用的大小
+const uploadFormOverhead = 1 << 20
+
 // @Summary Import Image
 // @Produce  json
 // @Param image formData file true "Image File"
@@ -17,43 +24,67 @@ import (
 // @Router /upload [post]
 func UploadImage(c *gin.Context) {
 	appG := app.Gin{C: c}
+
+	//请求体超过限制时直接拒绝，没有 Content-Length 的请求由 MaxBytesReader 在读取时截断
+	limit := int64(setting.AppSetting.ImageMaxSize) + uploadFormOverhead
+	if c.Request.ContentLength > limit {
+		appG.Response(http.StatusRequestEntityTooLarge, err.ERROR_UPLOAD_IMAGE_TOO_LARGE, nil)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
 	file, image, e := c.Request.FormFile("images")
 	if e != nil {
 		logging.Warn(e)
-		appG.Response(http.StatusInternalServerError, err.ERROR, nil)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
 		return
 	}
+	defer file.Close()
 
 	if image == nil {
 		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
 		return
 	}
 
-	imageName := upload.GetImageName(image.Filename)
-	fullPath := upload.GetImageFullPath()
 	savePath := upload.GetImagePath()
-	src := fullPath + imageName
 
-	if !upload.CheckImageExt(imageName) || !upload.CheckImageSize(file) {
+	if !upload.CheckImageExt(image.Filename) {
 		appG.Response(http.StatusBadRequest, err.ERROR_UPLOAD_CHECK_IMAGE_FORMAT, nil)
 		return
 	}
+	if !upload.CheckImageSize(image.Size) {
+		appG.Response(http.StatusRequestEntityTooLarge, err.ERROR_UPLOAD_IMAGE_TOO_LARGE, nil)
+		return
+	}
 
-	e = upload.CheckImage(fullPath)
+	e = upload.CheckImageContent(file, image.Filename)
+	if errors.Is(e, upload.ErrImageContent) {
+		appG.Response(http.StatusBadRequest, err.ERROR_UPLOAD_IMAGE_CONTENT, nil)
+		return
+	}
+	if errors.Is(e, upload.ErrImageDimensions) {
+		appG.Response(http.StatusBadRequest, err.ERROR_UPLOAD_IMAGE_DIMENSIONS, nil)
+		return
+	}
 	if e != nil {
 		logging.Warn(e)
 		appG.Response(http.StatusInternalServerError, err.ERROR_UPLOAD_CHECK_IMAGE_FAIL, nil)
 		return
 	}
 
-	if e := c.SaveUploadedFile(image, src); e != nil {
+	//图片按内容命名，内容相同的图片只保存一份
+	mediaService := media_service.Media{Filename: image.Filename, CreatedBy: jwt.GetClaims(c).Username}
+	media, variants, e := mediaService.Upload(file)
+	if e != nil {
 		logging.Warn(e)
 		appG.Response(http.StatusInternalServerError, err.ERROR_UPLOAD_SAVE_IMAGE_FAIL, nil)
 		return
 	}
 
-	appG.Response(http.StatusOK, err.SUCCESS, map[string]string{
-		"image_url":      upload.GetImageFullUrl(imageName),
-		"image_save_url": savePath + imageName,
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"media_id":       media.ID,
+		"image_url":      upload.GetImageFullUrl(media.Name),
+		"image_save_url": savePath + media.Name,
+		"variants":       variants,
 	})
 }
@@ -1,8 +1,14 @@
 package v1
 
 import (
+	"errors"
+	"gin-blog/middleware/jwt"
 	"gin-blog/pkg/app"
 	"gin-blog/pkg/err"
+	"gin-blog/pkg/export"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/poster"
+	"gin-blog/pkg/rbac"
 	"gin-blog/pkg/setting"
 	"gin-blog/pkg/util"
 	"gin-blog/service/article_service"
@@ -11,6 +17,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/unknwon/com"
 	"net/http"
+	"time"
 )
 
 // @Summary Get a single article
@@ -31,29 +38,26 @@ func GetArticle(c *gin.Context) {
 		return
 	}
 
-	articleService := article_service.Article{ID: id}
-	exists, e := articleService.ExistByID()
+	//Get 会缓存不存在的文章，不需要再单独检查是否存在
+	articleService := article_service.Article{ID: id, PublishedOnly: !canReadDrafts(c)}
+	article, e := articleService.Get()
 	if e != nil {
-		appG.Response(http.StatusInternalServerError, err.ERROR_CHECK_EXIST_ARTICLE_FAIL, nil)
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_ARTICLE_FAIL, nil)
 		return
 	}
-	if !exists {
+	if article == nil {
 		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_ARTICLE, nil)
 		return
 	}
 
-	article, e := articleService.Get()
-	if e != nil {
-		appG.Response(http.StatusInternalServerError, err.ERROR_GET_ARTICLE_FAIL, nil)
-		return
-	}
-
 	appG.Response(http.StatusOK, err.SUCCESS, article)
 }
 
 // @Summary Get multiple articles
 // @Produce  json
-// @Param tag_id body int false "TagID"
+// @Param tag_id query int false "TagID"
+// @Param tag_ids query string false "TagIDs, comma separated"
+// @Param tag_match query string false "any or all"
 // @Param state body int false "State"
 // @Param created_by body int false "CreatedBy"
 // @Success 200 {object} app.Response
@@ -63,17 +67,7 @@ func GetArticles(c *gin.Context) {
 	appG := app.Gin{C: c}
 	valid := validation.Validation{}
 
-	var state int = -1
-	if arg := c.Query("state"); arg != "" {
-		state = com.StrTo(arg).MustInt()
-		valid.Range(state, 0, 1, "state").Message("状态只允许0或1")
-	}
-
-	var tagId int = -1
-	if arg := c.Query("tag_id"); arg != "" {
-		tagId = com.StrTo(arg).MustInt()
-		valid.Min(tagId, 1, "tag_id").Message("标签ID必须大于0")
-	}
+	state, tagIds, matchAll := articleFilters(c, &valid)
 	if valid.HasErrors() {
 		app.MarkErrors(valid.Errors)
 		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
@@ -81,10 +75,12 @@ func GetArticles(c *gin.Context) {
 	}
 
 	articleService := article_service.Article{
-		TagID:    tagId,
-		State:    state,
-		PageNum:  util.GetPage(c),
-		PageSize: setting.AppSetting.PageSize,
+		TagIDs:        tagIds,
+		MatchAllTags:  matchAll,
+		State:         state,
+		PublishedOnly: !canReadDrafts(c),
+		PageNum:       util.GetPage(c),
+		PageSize:      setting.AppSetting.PageSize,
 	}
 
 	total, e := articleService.Count()
@@ -107,23 +103,31 @@ func GetArticles(c *gin.Context) {
 }
 
 type AddArticleForm struct {
-	TagID         int    `form:"tag_id" valid:"Required;Min(1)"`
+	TagIDs        []int  `form:"tag_ids" valid:"Required"`
 	Title         string `form:"title" valid:"Required;MaxSize(100)"`
 	Desc          string `form:"desc" valid:"Required;MaxSize(255)"`
 	Content       string `form:"content" valid:"Required;MaxSize(65535)"`
 	CreatedBy     string `form:"created_by" valid:"Required;MaxSize(100)"`
 	CoverImageUrl string `form:"cover_image_url" valid:"Required;MaxSize(255)"`
 	State         int    `form:"state" valid:"Range(0,1)"`
+	PublishAt     int    `form:"publish_at" valid:"Min(0)"`
+	UnpublishAt   int    `form:"unpublish_at" valid:"Min(0)"`
+}
+
+func (f *AddArticleForm) Valid(v *validation.Validation) {
+	validSchedule(v, f.PublishAt, f.UnpublishAt)
 }
 
 // @Summary Add article
 // @Produce  json
-// @Param tag_id body int true "TagID"
+// @Param tag_ids body []int true "TagIDs"
 // @Param title body string true "Title"
 // @Param desc body string true "Desc"
 // @Param content body string true "Content"
 // @Param created_by body string true "CreatedBy"
 // @Param state body int true "State"
+// @Param publish_at body int false "PublishAt"
+// @Param unpublish_at body int false "UnpublishAt"
 // @Success 200 {object} app.Response
 // @Failure 500 {object} app.Response
 // @Router /api/v1/articles [post]
@@ -140,8 +144,8 @@ func AddArticle(c *gin.Context) {
 		return
 	}
 
-	tagService := tag_service.Tag{ID: form.TagID}
-	exists, e := tagService.ExistByID()
+	tagService := tag_service.Tag{IDs: form.TagIDs}
+	exists, e := tagService.ExistByIDs()
 	if e != nil {
 		appG.Response(http.StatusInternalServerError, err.ERROR_EXIST_TAG_FAIL, nil)
 		return
@@ -152,13 +156,20 @@ func AddArticle(c *gin.Context) {
 		return
 	}
 
+	//作者只能以自己的名义创建文章
+	if claims := jwt.GetClaims(c); claims != nil && !rbac.HasPermission(claims.Role, rbac.PermManageArticle) {
+		form.CreatedBy = claims.Username
+	}
+
 	articleService := article_service.Article{
-		TagID:         form.TagID,
+		TagIDs:        form.TagIDs,
 		Title:         form.Title,
 		Desc:          form.Desc,
 		Content:       form.Content,
 		CoverImageUrl: form.CoverImageUrl,
 		State:         form.State,
+		PublishAt:     form.PublishAt,
+		UnpublishAt:   form.UnpublishAt,
 		CreatedBy:     form.CreatedBy,
 	}
 	if e := articleService.Add(); e != nil {
@@ -171,24 +182,32 @@ func AddArticle(c *gin.Context) {
 
 type EditArticleForm struct {
 	ID            int    `form:"id" valid:"Required;Min(1)"`
-	TagID         int    `form:"tag_id" valid:"Required;Min(1)"`
+	TagIDs        []int  `form:"tag_ids" valid:"Required"`
 	Title         string `form:"title" valid:"Required;MaxSize(100)"`
 	Desc          string `form:"desc" valid:"Required;MaxSize(255)"`
 	Content       string `form:"content" valid:"Required;MaxSize(65535)"`
 	ModifiedBy    string `form:"modified_by" valid:"Required;MaxSize(100)"`
 	CoverImageUrl string `form:"cover_image_url" valid:"Required;MaxSize(255)"`
 	State         int    `form:"state" valid:"Range(0,1)"`
+	PublishAt     int    `form:"publish_at" valid:"Min(0)"`
+	UnpublishAt   int    `form:"unpublish_at" valid:"Min(0)"`
+}
+
+func (f *EditArticleForm) Valid(v *validation.Validation) {
+	validSchedule(v, f.PublishAt, f.UnpublishAt)
 }
 
 // @Summary Update article
 // @Produce  json
 // @Param id path int true "ID"
-// @Param tag_id body string false "TagID"
+// @Param tag_ids body []int false "TagIDs"
 // @Param title body string false "Title"
 // @Param desc body string false "Desc"
 // @Param content body string false "Content"
 // @Param modified_by body string true "ModifiedBy"
 // @Param state body int false "State"
+// @Param publish_at body int false "PublishAt"
+// @Param unpublish_at body int false "UnpublishAt"
 // @Success 200 {object} app.Response
 // @Failure 500 {object} app.Response
 // @Router /api/v1/articles/{id} [put]
@@ -206,13 +225,15 @@ func EditArticle(c *gin.Context) {
 
 	articleService := article_service.Article{
 		ID:            form.ID,
-		TagID:         form.TagID,
+		TagIDs:        form.TagIDs,
 		Title:         form.Title,
 		Desc:          form.Desc,
 		Content:       form.Content,
 		CoverImageUrl: form.CoverImageUrl,
 		ModifiedBy:    form.ModifiedBy,
 		State:         form.State,
+		PublishAt:     form.PublishAt,
+		UnpublishAt:   form.UnpublishAt,
 	}
 	exists, e := articleService.ExistByID()
 	if e != nil {
@@ -224,8 +245,14 @@ func EditArticle(c *gin.Context) {
 		return
 	}
 
-	tagService := tag_service.Tag{ID: form.TagID}
-	exists, e = tagService.ExistByID()
+	httpCode, errCode = checkArticleOwner(c, &articleService)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	tagService := tag_service.Tag{IDs: form.TagIDs}
+	exists, e = tagService.ExistByIDs()
 	if e != nil {
 		appG.Response(http.StatusInternalServerError, err.ERROR_EXIST_TAG_FAIL, nil)
 		return
@@ -274,6 +301,12 @@ func DeleteArticle(c *gin.Context) {
 		return
 	}
 
+	httpCode, errCode := checkArticleOwner(c, &articleService)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
 	e = articleService.Delete()
 	if e != nil {
 		appG.Response(http.StatusInternalServerError, err.ERROR_DELETE_ARTICLE_FAIL, nil)
@@ -282,3 +315,199 @@ func DeleteArticle(c *gin.Context) {
 
 	appG.Response(http.StatusOK, err.SUCCESS, nil)
 }
+
+// @Summary Generate a share poster of an article
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/articles/{id}/poster [post]
+func GenerateArticlePoster(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{ID: id, PublishedOnly: !canReadDrafts(c)}
+	article, e := articleService.Get()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_ARTICLE_FAIL, nil)
+		return
+	}
+	if article == nil {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_ARTICLE, nil)
+		return
+	}
+
+	posterService := article_service.Poster{Article: article}
+	name, e := posterService.Generate()
+	if e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_GEN_ARTICLE_POSTER_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]string{
+		"poster_url":      poster.GetPosterFullUrl(name),
+		"poster_save_url": poster.GetPosterPath() + name,
+	})
+}
+
+// @Summary Export articles
+// @Produce  json
+// @Param format query string false "xlsx, csv or json"
+// @Param tag_id query int false "TagID"
+// @Param tag_ids query string false "TagIDs, comma separated"
+// @Param tag_match query string false "any or all"
+// @Param state query int false "State"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/articles/export [post]
+func ExportArticle(c *gin.Context) {
+	appG := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	state, tagIds, matchAll := articleFilters(c, &valid)
+	format := c.DefaultQuery("format", export.FormatXlsx)
+	validExportFormat(&valid, format)
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{
+		TagIDs:       tagIds,
+		MatchAllTags: matchAll,
+		State:        state,
+	}
+	filename, e := articleService.Export(format)
+	if e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_EXPORT_ARTICLE_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]string{
+		"export_url":      export.GetExcelFullUrl(filename),
+		"export_save_url": export.GetExcelPath() + filename,
+	})
+}
+
+// 请求体中除 zip 文件外 multipart 边界、表单字段等内容允许占用的大小
+const importFormOverhead = 1 << 20
+
+// @Summary Import articles from a zip of Markdown files with front matter
+// @Produce  json
+// @Param file formData file true "zip of .md files"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/articles/import [post]
+func ImportArticle(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	//与上传图片相同，在解析表单之前限制请求体大小，防止超大的请求写满临时目录
+	limit := int64(setting.AppSetting.ImportMaxSize) + importFormOverhead
+	if c.Request.ContentLength > limit {
+		appG.Response(http.StatusRequestEntityTooLarge, err.ERROR_IMPORT_ARTICLE_FILE, nil)
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+	file, header, e := c.Request.FormFile("file")
+	if e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+	defer file.Close()
+
+	if header.Size > int64(setting.AppSetting.ImportMaxSize) {
+		appG.Response(http.StatusRequestEntityTooLarge, err.ERROR_IMPORT_ARTICLE_FILE, nil)
+		return
+	}
+
+	//没有管理标签权限的用户不能通过导入创建标签
+	claims := jwt.GetClaims(c)
+	addTags := rbac.HasPermission(claims.Role, rbac.PermManageTag)
+	report, e := article_service.ImportMarkdown(file, header.Size, claims.Username, addTags)
+	if errors.Is(e, article_service.ErrInvalidImportZip) {
+		logging.Warn(e)
+		appG.Response(http.StatusBadRequest, err.ERROR_IMPORT_ARTICLE_FILE, nil)
+		return
+	}
+	if e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_IMPORT_ARTICLE_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, report)
+}
+
+// 没有管理全部文章权限的用户（如作者）只能修改、删除自己创建的文章
+func checkArticleOwner(c *gin.Context, articleService *article_service.Article) (int, int) {
+	claims := jwt.GetClaims(c)
+	if claims == nil {
+		return http.StatusUnauthorized, err.ERROR_AUTH_CHECK_TOKEN_FAIL
+	}
+	if rbac.HasPermission(claims.Role, rbac.PermManageArticle) {
+		return http.StatusOK, err.SUCCESS
+	}
+
+	article, e := articleService.Get()
+	if e != nil {
+		return http.StatusInternalServerError, err.ERROR_GET_ARTICLE_FAIL
+	}
+	if article == nil {
+		return http.StatusOK, err.ERROR_NOT_EXIST_ARTICLE
+	}
+	if article.CreatedBy != claims.Username {
+		return http.StatusForbidden, err.ERROR_AUTH_PERMISSION_DENIED
+	}
+
+	return http.StatusOK, err.SUCCESS
+}
+
+// 文章列表、搜索和导出共用的筛选条件：state 以及 app.TagFilters 中的标签条件
+func articleFilters(c *gin.Context, valid *validation.Validation) (int, []int, bool) {
+	var state int = -1
+	if arg := c.Query("state"); arg != "" {
+		state = com.StrTo(arg).MustInt()
+		valid.Range(state, 0, 1, "state").Message("状态只允许0或1")
+	}
+
+	tagIds, matchAll := app.TagFilters(c, valid)
+
+	return state, tagIds, matchAll
+}
+
+// 导出文章、标签时可选的文件格式
+func validExportFormat(v *validation.Validation, format string) {
+	if !export.ValidFormat(format) {
+		v.SetError("format", "导出格式只允许xlsx、csv或json")
+	}
+}
+
+// 没有写文章权限的读者只能看到已发布的文章
+func canReadDrafts(c *gin.Context) bool {
+	claims := jwt.GetClaims(c)
+	return claims != nil && rbac.HasPermission(claims.Role, rbac.PermWriteArticle)
+}
+
+// 下线时间必须晚于当前时间和发布时间
+func validSchedule(v *validation.Validation, publishAt, unpublishAt int) {
+	if unpublishAt == 0 {
+		return
+	}
+
+	if unpublishAt <= int(time.Now().Unix()) || unpublishAt <= publishAt {
+		v.SetError("unpublish_at", "下线时间必须晚于当前时间和发布时间")
+	}
+}
@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"gin-blog/middleware/jwt"
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/export"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/rbac"
+	"gin-blog/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// 下载各类导出文件需要的权限，与导出时需要的权限相同
+var exportPermissions = map[string]string{
+	"articles": rbac.PermWriteArticle,
+	"tags":     rbac.PermManageTag,
+}
+
+// @Summary Download an exported file
+// @Produce  octet-stream
+// @Param name path string true "Filename returned by the export"
+// @Success 200 {string} string
+// @Failure 404 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/exports/{name} [get]
+func DownloadExport(c *gin.Context) {
+	appG := app.Gin{C: c}
+	name := c.Param("name")
+
+	prefix, format, ok := export.ParseName(name)
+	permission, known := exportPermissions[prefix]
+	if !ok || !known {
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	claims := jwt.GetClaims(c)
+	if claims == nil || !rbac.HasPermission(claims.Role, permission) {
+		appG.Response(http.StatusForbidden, err.ERROR_AUTH_PERMISSION_DENIED, nil)
+		return
+	}
+
+	r, e := storage.Default.Get(export.GetExcelPath() + name)
+	if e == storage.ErrNotExist {
+		appG.Response(http.StatusNotFound, err.ERROR_NOT_EXIST_EXPORT, nil)
+		return
+	}
+	if e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_EXPORT_FAIL, nil)
+		return
+	}
+	defer r.Close()
+
+	c.DataFromReader(http.StatusOK, -1, export.ContentType(format), r, map[string]string{
+		"Content-Disposition": `attachment; filename="` + name + `"`,
+	})
+}
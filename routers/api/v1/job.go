@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/scheduler"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// @Summary Get scheduled jobs
+// @Produce  json
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/jobs [get]
+func GetJobs(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	jobs, e := scheduler.Jobs()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_JOBS_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": jobs,
+	})
+}
+
+// @Summary Run a scheduled job now
+// @Produce  json
+// @Param name path string true "Name"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/jobs/{name}/run [post]
+func RunJob(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	switch e := scheduler.Trigger(c.Param("name")); e {
+	case nil:
+		appG.Response(http.StatusOK, err.SUCCESS, nil)
+	case scheduler.ErrJobNotFound:
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_JOB, nil)
+	case scheduler.ErrJobRunning:
+		appG.Response(http.StatusOK, err.ERROR_JOB_RUNNING, nil)
+	default:
+		appG.Response(http.StatusInternalServerError, err.ERROR_RUN_JOB_FAIL, nil)
+	}
+}
+
+// @Summary Get the run history of a scheduled job
+// @Produce  json
+// @Param name path string true "Name"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/jobs/{name}/runs [get]
+func GetJobRuns(c *gin.Context) {
+	appG := app.Gin{C: c}
+	name := c.Param("name")
+
+	if !scheduler.Exists(name) {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_JOB, nil)
+		return
+	}
+
+	runs, e := scheduler.Runs(name, util.GetPage(c), setting.AppSetting.PageSize)
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_JOB_RUNS_FAIL, nil)
+		return
+	}
+
+	count, e := scheduler.CountRuns(name)
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_JOB_RUNS_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": runs,
+		"total": count,
+	})
+}
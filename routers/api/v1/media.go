@@ -0,0 +1,229 @@
+package v1
+
+import (
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/media_service"
+	"gin-blog/service/tag_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"net/http"
+)
+
+// @Summary Get uploaded media
+// @Produce  json
+// @Param q query string false "Filename or hash prefix"
+// @Param tag_id query int false "TagID"
+// @Param tag_ids query string false "TagIDs, comma separated"
+// @Param created_by query string false "CreatedBy"
+// @Param mime_type query string false "MimeType"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/media [get]
+func GetMediaList(c *gin.Context) {
+	appG := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	var tagIds []int
+	if arg := c.Query("tag_id"); arg != "" {
+		tagIds = append(tagIds, com.StrTo(arg).MustInt())
+	}
+	tagIds = append(tagIds, util.GetIntList(c, "tag_ids")...)
+	for _, tagId := range tagIds {
+		valid.Min(tagId, 1, "tag_id").Message("标签ID必须大于0")
+	}
+
+	query := c.Query("q")
+	valid.MaxSize(query, 100, "q").Message("搜索内容最长为100字符")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	mediaService := media_service.Media{
+		TagIDs:    tagIds,
+		Query:     query,
+		CreatedBy: c.Query("created_by"),
+		MimeType:  c.Query("mime_type"),
+		PageNum:   util.GetPage(c),
+		PageSize:  setting.AppSetting.PageSize,
+	}
+
+	total, e := mediaService.Count()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_COUNT_MEDIA_FAIL, nil)
+		return
+	}
+
+	list, e := mediaService.GetAll()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_MEDIA_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": list,
+		"total": total,
+	})
+}
+
+// @Summary Get a single media with its variants and the articles using it
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/media/{id} [get]
+func GetMedia(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	mediaService := media_service.Media{ID: id}
+	media, e := mediaService.Get()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_MEDIA_FAIL, nil)
+		return
+	}
+	if media == nil {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_MEDIA, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, media)
+}
+
+type EditMediaForm struct {
+	ID     int   `form:"id" valid:"Required;Min(1)"`
+	TagIDs []int `form:"tag_ids"`
+}
+
+// @Summary Update the tags of a media
+// @Produce  json
+// @Param id path int true "ID"
+// @Param tag_ids body string false "TagIDs, empty to remove all tags"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/media/{id} [put]
+func EditMedia(c *gin.Context) {
+	var (
+		appG = app.Gin{C: c}
+		form = EditMediaForm{ID: com.StrTo(c.Param("id")).MustInt()}
+	)
+
+	httpCode, errCode := app.BindAndValid(c, &form)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	mediaService := media_service.Media{ID: form.ID, TagIDs: form.TagIDs}
+	media, e := mediaService.Get()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_MEDIA_FAIL, nil)
+		return
+	}
+	if media == nil {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_MEDIA, nil)
+		return
+	}
+
+	if len(form.TagIDs) > 0 {
+		tagService := tag_service.Tag{IDs: form.TagIDs}
+		exists, e := tagService.ExistByIDs()
+		if e != nil {
+			appG.Response(http.StatusInternalServerError, err.ERROR_EXIST_TAG_FAIL, nil)
+			return
+		}
+		if !exists {
+			appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_TAG, nil)
+			return
+		}
+	}
+
+	if e := mediaService.EditTags(); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_EDIT_MEDIA_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+// @Summary Delete a media and all of its files
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/media/{id} [delete]
+func DeleteMedia(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	mediaService := media_service.Media{ID: id}
+	media, e := mediaService.Get()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_MEDIA_FAIL, nil)
+		return
+	}
+	if media == nil {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_MEDIA, nil)
+		return
+	}
+
+	//仍被文章（包括回收站中的文章）引用的文件不能删除
+	if len(media.ArticleIDs) > 0 {
+		appG.Response(http.StatusOK, err.ERROR_MEDIA_IN_USE, map[string]interface{}{
+			"article_ids": media.ArticleIDs,
+		})
+		return
+	}
+
+	if e := mediaService.Delete(media.Media); e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_DELETE_MEDIA_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+// @Summary Get uploaded files not referenced by any article
+// @Produce  json
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/media/orphans [get]
+func GetMediaOrphans(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	orphans, e := media_service.FindOrphans()
+	if e != nil {
+		logging.Warn(e)
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_MEDIA_ORPHANS_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": orphans,
+		"total": len(orphans),
+	})
+}
@@ -0,0 +1,189 @@
+package v1
+
+import (
+	"gin-blog/middleware/jwt"
+	"gin-blog/models"
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/article_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"net/http"
+)
+
+// @Summary Get the revisions of an article
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/articles/{id}/revisions [get]
+func GetArticleRevisions(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	httpCode, errCode := checkRevisionArticle(c, id)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	revisionService := article_service.Revision{
+		ArticleID: id,
+		PageNum:   util.GetPage(c),
+		PageSize:  setting.AppSetting.PageSize,
+	}
+	revisions, e := revisionService.GetAll()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_REVISIONS_FAIL, nil)
+		return
+	}
+
+	count, e := revisionService.Count()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_COUNT_REVISION_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": revisions,
+		"total": count,
+	})
+}
+
+// @Summary Show a unified diff between two revisions of an article
+// @Produce  json
+// @Param id path int true "ID"
+// @Param rid path int true "RevisionID"
+// @Param to query int false "RevisionID to compare with, defaults to the current version"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/articles/{id}/revisions/{rid}/diff [get]
+func DiffArticleRevision(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	rid := com.StrTo(c.Param("rid")).MustInt()
+	to := com.StrTo(c.DefaultQuery("to", "0")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+	valid.Min(rid, 1, "rid").Message("版本ID必须大于0")
+	valid.Min(to, 0, "to").Message("版本ID不能小于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	httpCode, errCode := checkRevisionArticle(c, id)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	revisionService := article_service.Revision{ID: rid, ArticleID: id}
+	from, httpCode, errCode := getRevision(&revisionService)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	var target *models.ArticleRevision
+	if to > 0 {
+		toService := article_service.Revision{ID: to, ArticleID: id}
+		if target, httpCode, errCode = getRevision(&toService); errCode != err.SUCCESS {
+			appG.Response(httpCode, errCode, nil)
+			return
+		}
+	}
+
+	result, e := revisionService.Diff(from, target)
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_REVISION_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"from": rid,
+		"to":   to,
+		"diff": result,
+	})
+}
+
+// @Summary Restore an article to a revision
+// @Produce  json
+// @Param id path int true "ID"
+// @Param rid path int true "RevisionID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/articles/{id}/revisions/{rid}/restore [post]
+func RestoreArticleRevision(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	rid := com.StrTo(c.Param("rid")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+	valid.Min(rid, 1, "rid").Message("版本ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	httpCode, errCode := checkRevisionArticle(c, id)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	revisionService := article_service.Revision{ID: rid, ArticleID: id, ModifiedBy: jwt.GetClaims(c).Username}
+	revision, httpCode, errCode := getRevision(&revisionService)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	if e := revisionService.Restore(revision); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_RESTORE_REVISION_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+// checkRevisionArticle 历史版本中可能包含未发布的内容，文章不存在或在回收站中时返回 404，作者只能查看、恢复自己文章的历史版本
+func checkRevisionArticle(c *gin.Context, id int) (int, int) {
+	articleService := article_service.Article{ID: id}
+	exists, e := articleService.ExistByID()
+	if e != nil {
+		return http.StatusInternalServerError, err.ERROR_CHECK_EXIST_ARTICLE_FAIL
+	}
+	if !exists {
+		return http.StatusOK, err.ERROR_NOT_EXIST_ARTICLE
+	}
+
+	return checkArticleOwner(c, &articleService)
+}
+
+func getRevision(revisionService *article_service.Revision) (*models.ArticleRevision, int, int) {
+	revision, e := revisionService.Get()
+	if e != nil {
+		return nil, http.StatusInternalServerError, err.ERROR_GET_REVISION_FAIL
+	}
+	if revision == nil {
+		return nil, http.StatusOK, err.ERROR_NOT_EXIST_REVISION
+	}
+
+	return revision, http.StatusOK, err.SUCCESS
+}
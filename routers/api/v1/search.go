@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/article_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// @Summary Full-text search over articles
+// @Produce  json
+// @Param q query string true "Query"
+// @Param tag_id query int false "TagID"
+// @Param tag_ids query string false "TagIDs, comma separated"
+// @Param tag_match query string false "any or all"
+// @Param state query int false "State"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/search [get]
+func SearchArticles(c *gin.Context) {
+	appG := app.Gin{C: c}
+	valid := validation.Validation{}
+
+	q := strings.TrimSpace(c.Query("q"))
+	valid.Required(q, "q").Message("搜索词不能为空")
+	valid.MaxSize(q, 100, "q").Message("搜索词最长为100字符")
+
+	state, tagIds, matchAll := articleFilters(c, &valid)
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	searchService := article_service.Search{
+		Query:         q,
+		TagIDs:        tagIds,
+		MatchAllTags:  matchAll,
+		State:         state,
+		PublishedOnly: !canReadDrafts(c),
+		PageNum:       util.GetPage(c),
+		PageSize:      setting.AppSetting.PageSize,
+	}
+	results, total, e := searchService.Do()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_SEARCH_ARTICLES_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": results,
+		"total": total,
+	})
+}
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"errors"
+	"gin-blog/middleware/jwt"
 	"gin-blog/pkg/app"
 	"gin-blog/pkg/err"
 	"gin-blog/pkg/export"
@@ -12,6 +14,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/unknwon/com"
 	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 // @Summary Get multiple article tags
@@ -197,18 +203,27 @@ func DeleteTag(c *gin.Context) {
 
 func ExportTag(c *gin.Context) {
 	appG := app.Gin{C: c}
+	valid := validation.Validation{}
 	name := c.PostForm("name")
 	state := -1
 	if arg := c.PostForm("state"); arg != "" {
 		state = com.StrTo(arg).MustInt()
 	}
+	format := c.DefaultPostForm("format", export.FormatXlsx)
+	validExportFormat(&valid, format)
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
 
 	tagService := tag_service.Tag{
 		Name:  name,
 		State: state,
 	}
 
-	filename, e := tagService.Export()
+	filename, e := tagService.Export(format)
 	if e != nil {
 		appG.Response(http.StatusOK, err.ERROR_EXPORT_TAG_FAIL, nil)
 		return
@@ -219,23 +234,63 @@ func ExportTag(c *gin.Context) {
 	})
 }
 
+// @Summary Import tags from an xlsx or csv file
+// @Produce  json
+// @Param file formData file true "xlsx or csv file, the first row is the header"
+// @Param on_exist formData string false "skip or update, defaults to skip"
+// @Param atomic formData bool false "import nothing if any row fails"
+// @Param dry_run formData bool false "only report what would be imported"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/tags/import [post]
 func ImportTag(c *gin.Context) {
 	appG := app.Gin{C: c}
+	valid := validation.Validation{}
 
-	file, _, e := c.Request.FormFile("file")
+	file, header, e := c.Request.FormFile("file")
 	if e != nil {
 		logging.Warn(e)
-		appG.Response(http.StatusOK, err.ERROR, nil)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
 		return
 	}
+	defer file.Close()
+
+	format := strings.ToLower(strings.TrimPrefix(path.Ext(header.Filename), "."))
+	valid.Match(format, regexp.MustCompile("^(xlsx|csv)$"), "file").Message("只支持导入xlsx或csv文件")
+	onExist := c.DefaultPostForm("on_exist", "skip")
+	valid.Match(onExist, regexp.MustCompile("^(skip|update)$"), "on_exist").Message("名称已存在时只允许skip或update")
+	//1、true 等都视为开启，无法解析时视为关闭
+	atomic, _ := strconv.ParseBool(c.PostForm("atomic"))
+	dryRun, _ := strconv.ParseBool(c.PostForm("dry_run"))
 
-	tagService := tag_service.Tag{}
-	e = tagService.Import(file)
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	tagService := tag_service.Tag{CreatedBy: jwt.GetClaims(c).Username}
+	report, e := tagService.Import(file, format, tag_service.ImportOptions{
+		Update: onExist == "update",
+		Atomic: atomic,
+		DryRun: dryRun,
+	})
+	if errors.Is(e, tag_service.ErrInvalidImportFile) {
+		logging.Warn(e)
+		appG.Response(http.StatusBadRequest, err.ERROR_IMPORT_TAG_FILE, nil)
+		return
+	}
 	if e != nil {
 		logging.Warn(e)
-		appG.Response(http.StatusOK, err.ERROR_IMPORT_TAG_FAIL, nil)
+		appG.Response(http.StatusInternalServerError, err.ERROR_IMPORT_TAG_FAIL, nil)
 		return
 	}
 
-	appG.Response(http.StatusOK, err.SUCCESS, nil)
+	//整体导入失败时同样返回导入结果，便于修改文件后重新导入
+	if atomic && !dryRun && !report.Committed {
+		appG.Response(http.StatusOK, err.ERROR_IMPORT_TAG_FAIL, report)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, report)
 }
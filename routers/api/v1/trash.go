@@ -0,0 +1,233 @@
+package v1
+
+import (
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/article_service"
+	"gin-blog/service/tag_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"net/http"
+)
+
+// @Summary Get soft-deleted articles
+// @Produce  json
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/trash/articles [get]
+func GetTrashArticles(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	articleService := article_service.Article{
+		PageNum:  util.GetPage(c),
+		PageSize: setting.AppSetting.PageSize,
+	}
+
+	total, e := articleService.CountDeleted()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_COUNT_ARTICLE_FAIL, nil)
+		return
+	}
+
+	articles, e := articleService.GetDeleted()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_ARTICLES_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": articles,
+		"total": total,
+	})
+}
+
+// @Summary Restore a soft-deleted article
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/trash/articles/{id}/restore [post]
+func RestoreArticle(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id, ok := trashID(c)
+	if !ok {
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{ID: id}
+	exists, e := articleService.ExistDeletedByID()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_CHECK_EXIST_ARTICLE_FAIL, nil)
+		return
+	}
+	if !exists {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_TRASH_ARTICLE, nil)
+		return
+	}
+
+	if e := articleService.Restore(); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_RESTORE_ARTICLE_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+// @Summary Permanently delete a soft-deleted article
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/trash/articles/{id} [delete]
+func PurgeArticle(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id, ok := trashID(c)
+	if !ok {
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	articleService := article_service.Article{ID: id}
+	exists, e := articleService.ExistDeletedByID()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_CHECK_EXIST_ARTICLE_FAIL, nil)
+		return
+	}
+	if !exists {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_TRASH_ARTICLE, nil)
+		return
+	}
+
+	if e := articleService.Purge(); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_PURGE_ARTICLE_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+// @Summary Get soft-deleted tags
+// @Produce  json
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/trash/tags [get]
+func GetTrashTags(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	tagService := tag_service.Tag{
+		PageNum:  util.GetPage(c),
+		PageSize: setting.AppSetting.PageSize,
+	}
+
+	tags, e := tagService.GetDeleted()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_TAGS_FAIL, nil)
+		return
+	}
+
+	count, e := tagService.CountDeleted()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_COUNT_TAG_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": tags,
+		"total": count,
+	})
+}
+
+// @Summary Restore a soft-deleted tag
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/trash/tags/{id}/restore [post]
+func RestoreTag(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id, ok := trashID(c)
+	if !ok {
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	tagService := tag_service.Tag{ID: id}
+	tag, e := tagService.GetDeletedByID()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_EXIST_TAG_FAIL, nil)
+		return
+	}
+	if tag.ID == 0 {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_TRASH_TAG, nil)
+		return
+	}
+
+	//删除后可能又新建了同名标签，恢复后会出现重复
+	tagService.Name = tag.Name
+	exists, e := tagService.ExistByName()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_EXIST_TAG_FAIL, nil)
+		return
+	}
+	if exists {
+		appG.Response(http.StatusOK, err.ERROR_EXIST_TAG, nil)
+		return
+	}
+
+	if e := tagService.Restore(); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_RESTORE_TAG_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+// @Summary Permanently delete a soft-deleted tag
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/trash/tags/{id} [delete]
+func PurgeTag(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id, ok := trashID(c)
+	if !ok {
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	tagService := tag_service.Tag{ID: id}
+	tag, e := tagService.GetDeletedByID()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_EXIST_TAG_FAIL, nil)
+		return
+	}
+	if tag.ID == 0 {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_TRASH_TAG, nil)
+		return
+	}
+
+	if e := tagService.Purge(); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_PURGE_TAG_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+func trashID(c *gin.Context) (int, bool) {
+	id := com.StrTo(c.Param("id")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		return 0, false
+	}
+
+	return id, true
+}
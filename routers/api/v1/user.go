@@ -0,0 +1,298 @@
+package v1
+
+import (
+	"gin-blog/middleware/jwt"
+	"gin-blog/pkg/app"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/rbac"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/auth_service"
+	"github.com/astaxie/beego/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/unknwon/com"
+	"net/http"
+)
+
+// @Summary Get multiple users
+// @Produce  json
+// @Param username query string false "Username"
+// @Param role query string false "Role"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/users [get]
+func GetUsers(c *gin.Context) {
+	appG := app.Gin{C: c}
+
+	authService := auth_service.Auth{
+		Username: c.Query("username"),
+		Role:     c.Query("role"),
+		PageNum:  util.GetPage(c),
+		PageSize: setting.AppSetting.PageSize,
+	}
+	users, e := authService.GetAll()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_USERS_FAIL, nil)
+		return
+	}
+
+	count, e := authService.Count()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_COUNT_USER_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, map[string]interface{}{
+		"lists": users,
+		"total": count,
+	})
+}
+
+// @Summary Get a single user
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/users/{id} [get]
+func GetUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+	id := com.StrTo(c.Param("id")).MustInt()
+	valid := validation.Validation{}
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	authService := auth_service.Auth{ID: id}
+	exists, e := authService.ExistByID()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_CHECK_EXIST_USER_FAIL, nil)
+		return
+	}
+	if !exists {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_USER, nil)
+		return
+	}
+
+	user, e := authService.Get()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_GET_USER_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, user)
+}
+
+type AddUserForm struct {
+	Username string `form:"username" valid:"Required;MaxSize(50)"`
+	Password string `form:"password" valid:"Required;MinSize(6);MaxSize(72)"`
+	Role     string `form:"role" valid:"Required"`
+}
+
+// @Summary Add user
+// @Produce  json
+// @Param username body string true "Username"
+// @Param password body string true "Password"
+// @Param role body string true "admin, editor, author or reader"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/users [post]
+func AddUser(c *gin.Context) {
+	var (
+		appG = app.Gin{C: c}
+		form AddUserForm
+	)
+
+	httpCode, errCode := app.BindAndValid(c, &form)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+	if !rbac.IsValidRole(form.Role) {
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	authService := auth_service.Auth{
+		Username: form.Username,
+		Password: form.Password,
+		Role:     form.Role,
+	}
+	exists, e := authService.ExistByUsername()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_CHECK_EXIST_USER_FAIL, nil)
+		return
+	}
+	if exists {
+		appG.Response(http.StatusOK, err.ERROR_EXIST_USER, nil)
+		return
+	}
+
+	if e := authService.Add(); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_ADD_USER_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+type EditUserForm struct {
+	ID       int    `form:"id" valid:"Required;Min(1)"`
+	Username string `form:"username" valid:"MaxSize(50)"`
+	Password string `form:"password" valid:"MaxSize(72)"`
+	Role     string `form:"role"`
+}
+
+// @Summary Update user
+// @Produce  json
+// @Param id path int true "ID"
+// @Param username body string false "Username"
+// @Param password body string false "Password"
+// @Param role body string false "admin, editor, author or reader"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/users/{id} [put]
+func EditUser(c *gin.Context) {
+	var (
+		appG = app.Gin{C: c}
+		form = EditUserForm{ID: com.StrTo(c.Param("id")).MustInt()}
+	)
+
+	httpCode, errCode := app.BindAndValid(c, &form)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+	if (form.Password != "" && len(form.Password) < 6) || (form.Role != "" && !rbac.IsValidRole(form.Role)) {
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	authService := auth_service.Auth{ID: form.ID}
+	exists, e := authService.ExistByID()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_CHECK_EXIST_USER_FAIL, nil)
+		return
+	}
+	if !exists {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_USER, nil)
+		return
+	}
+
+	if form.Username != "" {
+		user, e := authService.Get()
+		if e != nil {
+			appG.Response(http.StatusInternalServerError, err.ERROR_GET_USER_FAIL, nil)
+			return
+		}
+
+		authService.Username = form.Username
+		if user.Username != form.Username {
+			exists, e = authService.ExistByUsername()
+			if e != nil {
+				appG.Response(http.StatusInternalServerError, err.ERROR_CHECK_EXIST_USER_FAIL, nil)
+				return
+			}
+			if exists {
+				appG.Response(http.StatusOK, err.ERROR_EXIST_USER, nil)
+				return
+			}
+		}
+	}
+
+	authService.Password = form.Password
+	authService.Role = form.Role
+	if e := authService.Edit(); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_EDIT_USER_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+// @Summary Delete user
+// @Produce  json
+// @Param id path int true "ID"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/users/{id} [delete]
+func DeleteUser(c *gin.Context) {
+	appG := app.Gin{C: c}
+	valid := validation.Validation{}
+	id := com.StrTo(c.Param("id")).MustInt()
+	valid.Min(id, 1, "id").Message("ID必须大于0")
+
+	if valid.HasErrors() {
+		app.MarkErrors(valid.Errors)
+		appG.Response(http.StatusBadRequest, err.INVALID_PARAMS, nil)
+		return
+	}
+
+	authService := auth_service.Auth{ID: id}
+	exists, e := authService.ExistByID()
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_CHECK_EXIST_USER_FAIL, nil)
+		return
+	}
+	if !exists {
+		appG.Response(http.StatusOK, err.ERROR_NOT_EXIST_USER, nil)
+		return
+	}
+
+	if e := authService.Delete(); e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_DELETE_USER_FAIL, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
+
+type ChangePasswordForm struct {
+	OldPassword string `form:"old_password" valid:"Required;MaxSize(72)"`
+	NewPassword string `form:"new_password" valid:"Required;MinSize(6);MaxSize(72)"`
+}
+
+// @Summary Change the password of the current user
+// @Produce  json
+// @Param old_password body string true "OldPassword"
+// @Param new_password body string true "NewPassword"
+// @Success 200 {object} app.Response
+// @Failure 500 {object} app.Response
+// @Router /api/v1/user/password [put]
+func ChangePassword(c *gin.Context) {
+	var (
+		appG = app.Gin{C: c}
+		form ChangePasswordForm
+	)
+
+	httpCode, errCode := app.BindAndValid(c, &form)
+	if errCode != err.SUCCESS {
+		appG.Response(httpCode, errCode, nil)
+		return
+	}
+
+	claims := jwt.GetClaims(c)
+	if claims == nil {
+		appG.Response(http.StatusUnauthorized, err.ERROR_AUTH_CHECK_TOKEN_FAIL, nil)
+		return
+	}
+
+	authService := auth_service.Auth{
+		Username: claims.Username,
+		Password: form.OldPassword,
+	}
+	ok, e := authService.ChangePassword(form.NewPassword)
+	if e != nil {
+		appG.Response(http.StatusInternalServerError, err.ERROR_CHANGE_PASSWORD_FAIL, nil)
+		return
+	}
+	if !ok {
+		appG.Response(http.StatusOK, err.ERROR_WRONG_PASSWORD, nil)
+		return
+	}
+
+	appG.Response(http.StatusOK, err.SUCCESS, nil)
+}
@@ -1,15 +1,21 @@
 package routers
 
 import (
+	"gin-blog/middleware/httpcache"
 	"gin-blog/middleware/jwt"
-	"gin-blog/pkg/export"
+	"gin-blog/middleware/ratelimit"
+	"gin-blog/pkg/poster"
+	"gin-blog/pkg/rbac"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
 	"gin-blog/pkg/upload"
 	"gin-blog/routers/api"
+	"gin-blog/routers/api/public"
 	v1 "gin-blog/routers/api/v1"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
-	"net/http"
+	"time"
 )
 
 func InitRouter() *gin.Engine {
@@ -19,11 +25,45 @@ func InitRouter() *gin.Engine {
 
 	r.Use(gin.Recovery())
 
-	r.StaticFS("/upload/images", http.Dir(upload.GetImageFullPath()))
-	r.StaticFS("/export", http.Dir(export.GetExcelFullPath()))
+	//使用本地存储时由本服务提供文件访问，不列出目录内容，S3 存储的文件直接通过存储桶或 CDN 访问。
+	//导出的文件可能包含草稿，只能通过需要登录的 /api/v1/exports/:name 下载
+	if local, ok := storage.Default.(*storage.Local); ok {
+		r.StaticFS("/upload/images", gin.Dir(local.FullPath(upload.GetImagePath()), false))
+		r.StaticFS("/poster", gin.Dir(local.FullPath(poster.GetPosterPath()), false))
+	}
 	r.POST("/auth", api.GetAuth)
+	r.POST("/auth/setup", api.Setup)
+	r.POST("/auth/refresh", api.RefreshAuth)
+	r.POST("/auth/logout", jwt.JWT(), api.Logout)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	r.POST("/upload", api.UploadImage)
+	r.POST("/upload", jwt.JWT(), api.UploadImage)
+
+	//公开的只读接口，不需要登录，只返回已发布的文章和启用的标签
+	apiPublic := r.Group("/api/public")
+	apiPublic.Use(httpcache.HTTPCache(setting.AppSetting.PublicMaxAge))
+	{
+		//获取文章列表
+		apiPublic.GET("/articles", public.GetArticles)
+		//获取指定文章
+		apiPublic.GET("/articles/:id", public.GetArticle)
+		//获取标签列表
+		apiPublic.GET("/tags", public.GetTags)
+	}
+
+	//订阅和站点地图只包含已发布的文章，标签订阅只包含该标签下的文章
+	site := r.Group("/")
+	site.Use(httpcache.HTTPCache(setting.AppSetting.PublicMaxAge))
+	{
+		site.GET("/feed.xml", api.GetRSS)
+		site.GET("/atom.xml", api.GetAtom)
+		site.GET("/feed.json", api.GetJSONFeed)
+		site.GET("/tags/:id/feed.xml", api.GetRSS)
+		site.GET("/tags/:id/atom.xml", api.GetAtom)
+		site.GET("/tags/:id/feed.json", api.GetJSONFeed)
+		site.GET("/sitemap.xml", api.GetSitemap)
+		site.GET("/sitemaps/:page", api.GetSitemapPage)
+		site.GET("/robots.txt", api.GetRobots)
+	}
 
 	apiv1 := r.Group("/api/v1")
 	apiv1.Use(jwt.JWT())
@@ -31,25 +71,81 @@ func InitRouter() *gin.Engine {
 		//获取标签列表
 		apiv1.GET("/tags", v1.GetTags)
 		//新建标签
-		apiv1.POST("/tags", v1.AddTag)
+		apiv1.POST("/tags", jwt.Permission(rbac.PermManageTag), v1.AddTag)
 		//更新指定标签
-		apiv1.PUT("/tags/:id", v1.EditTag)
+		apiv1.PUT("/tags/:id", jwt.Permission(rbac.PermManageTag), v1.EditTag)
 		//删除指定标签
-		apiv1.DELETE("/tags/:id", v1.DeleteTag)
+		apiv1.DELETE("/tags/:id", jwt.Permission(rbac.PermManageTag), v1.DeleteTag)
 		//导出标签
-		r.POST("/tags/export", v1.ExportTag)
+		apiv1.POST("/tags/export", jwt.Permission(rbac.PermManageTag), v1.ExportTag)
 		//导入标签
-		r.POST("/tags/import", v1.ImportTag)
+		apiv1.POST("/tags/import", jwt.Permission(rbac.PermManageTag), v1.ImportTag)
 		//获取文章列表
 		apiv1.GET("/articles", v1.GetArticles)
 		//获取指定文章
 		apiv1.GET("/articles/:id", v1.GetArticle)
 		//新建文章
-		apiv1.POST("/articles", v1.AddArticle)
-		//更新指定文章
-		apiv1.PUT("/articles/:id", v1.EditArticle)
-		//删除指定文章
-		apiv1.DELETE("/articles/:id", v1.DeleteArticle)
+		apiv1.POST("/articles", jwt.Permission(rbac.PermWriteArticle), v1.AddArticle)
+		//更新指定文章，作者只能更新自己的文章
+		apiv1.PUT("/articles/:id", jwt.Permission(rbac.PermWriteArticle), v1.EditArticle)
+		//删除指定文章，作者只能删除自己的文章
+		apiv1.DELETE("/articles/:id", jwt.Permission(rbac.PermWriteArticle), v1.DeleteArticle)
+		//导出文章，筛选条件与文章列表相同
+		apiv1.POST("/articles/export", jwt.Permission(rbac.PermWriteArticle), v1.ExportArticle)
+		//下载导出的文件，需要与导出时相同的权限
+		apiv1.GET("/exports/:name", v1.DownloadExport)
+		//从Markdown文件的zip压缩包导入文章
+		apiv1.POST("/articles/import", jwt.Permission(rbac.PermWriteArticle), v1.ImportArticle)
+		//生成文章的分享海报，每个用户每分钟最多生成 PosterRateLimit 次
+		apiv1.POST("/articles/:id/poster", jwt.Permission(rbac.PermWriteArticle), ratelimit.PerUser(setting.AppSetting.PosterRateLimit, time.Minute), v1.GenerateArticlePoster)
+		//全文搜索文章
+		apiv1.GET("/search", v1.SearchArticles)
+		//获取文章的历史版本，作者只能查看自己的文章
+		apiv1.GET("/articles/:id/revisions", jwt.Permission(rbac.PermWriteArticle), v1.GetArticleRevisions)
+		//比较两个历史版本，不指定to时与当前内容比较，作者只能查看自己的文章
+		apiv1.GET("/articles/:id/revisions/:rid/diff", jwt.Permission(rbac.PermWriteArticle), v1.DiffArticleRevision)
+		//将文章恢复为指定的历史版本，作者只能恢复自己的文章
+		apiv1.POST("/articles/:id/revisions/:rid/restore", jwt.Permission(rbac.PermWriteArticle), v1.RestoreArticleRevision)
+		//获取回收站中的文章
+		apiv1.GET("/trash/articles", jwt.Permission(rbac.PermManageArticle), v1.GetTrashArticles)
+		//恢复回收站中的文章
+		apiv1.POST("/trash/articles/:id/restore", jwt.Permission(rbac.PermManageArticle), v1.RestoreArticle)
+		//彻底删除回收站中的文章
+		apiv1.DELETE("/trash/articles/:id", jwt.Permission(rbac.PermManageArticle), v1.PurgeArticle)
+		//获取回收站中的标签
+		apiv1.GET("/trash/tags", jwt.Permission(rbac.PermManageTag), v1.GetTrashTags)
+		//恢复回收站中的标签
+		apiv1.POST("/trash/tags/:id/restore", jwt.Permission(rbac.PermManageTag), v1.RestoreTag)
+		//彻底删除回收站中的标签
+		apiv1.DELETE("/trash/tags/:id", jwt.Permission(rbac.PermManageTag), v1.PurgeTag)
+		//获取媒体文件列表
+		apiv1.GET("/media", jwt.Permission(rbac.PermWriteArticle), v1.GetMediaList)
+		//获取没有被文章引用的上传文件
+		apiv1.GET("/media/orphans", jwt.Permission(rbac.PermManageSystem), v1.GetMediaOrphans)
+		//获取指定媒体文件
+		apiv1.GET("/media/:id", jwt.Permission(rbac.PermWriteArticle), v1.GetMedia)
+		//修改媒体文件的标签
+		apiv1.PUT("/media/:id", jwt.Permission(rbac.PermWriteArticle), v1.EditMedia)
+		//删除媒体文件，仍被文章引用时不能删除
+		apiv1.DELETE("/media/:id", jwt.Permission(rbac.PermManageArticle), v1.DeleteMedia)
+		//获取用户列表
+		apiv1.GET("/users", jwt.Permission(rbac.PermManageUser), v1.GetUsers)
+		//获取指定用户
+		apiv1.GET("/users/:id", jwt.Permission(rbac.PermManageUser), v1.GetUser)
+		//新建用户
+		apiv1.POST("/users", jwt.Permission(rbac.PermManageUser), v1.AddUser)
+		//更新指定用户
+		apiv1.PUT("/users/:id", jwt.Permission(rbac.PermManageUser), v1.EditUser)
+		//删除指定用户
+		apiv1.DELETE("/users/:id", jwt.Permission(rbac.PermManageUser), v1.DeleteUser)
+		//修改当前用户的密码
+		apiv1.PUT("/user/password", v1.ChangePassword)
+		//获取定时任务列表
+		apiv1.GET("/jobs", jwt.Permission(rbac.PermManageSystem), v1.GetJobs)
+		//手动执行定时任务
+		apiv1.POST("/jobs/:name/run", jwt.Permission(rbac.PermManageSystem), v1.RunJob)
+		//获取定时任务的执行记录
+		apiv1.GET("/jobs/:name/runs", jwt.Permission(rbac.PermManageSystem), v1.GetJobRuns)
 	}
 
 	return r
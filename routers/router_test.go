@@ -0,0 +1,247 @@
+package routers
+
+import (
+	"encoding/json"
+	"gin-blog/models"
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/export"
+	"gin-blog/pkg/gredis"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"gin-blog/pkg/util"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupTest 使用临时目录中的本地存储和 miniredis
+func setupTest(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	//日志路径相对于当前目录
+	wd, _ := os.Getwd()
+	dir, e := filepath.Rel(wd, t.TempDir())
+	if e != nil {
+		t.Fatal(e)
+	}
+	setting.AppSetting.RuntimeRootPath = dir + "/"
+	setting.AppSetting.LogSavePath = ""
+	setting.AppSetting.LogSaveName = "log"
+	setting.AppSetting.LogFileExt = "log"
+	setting.AppSetting.TimeFormat = "20060102"
+	setting.AppSetting.ImageSavePath = "upload/images/"
+	setting.AppSetting.ExportSavePath = "export/"
+	setting.AppSetting.PosterSavePath = "poster/"
+	setting.AppSetting.PrefixUrl = "http://localhost:8080"
+	setting.AppSetting.JwtSecret = "test"
+	setting.AppSetting.JwtExpire = time.Hour
+	logging.Setup()
+
+	mr := miniredis.RunT(t)
+	setting.RedisSetting = &setting.Redis{Host: mr.Addr(), MaxIdle: 1, MaxActive: 10}
+	gredis.Setup()
+
+	storage.Default = storage.NewLocal(t.TempDir(), setting.AppSetting.PrefixUrl)
+	gin.SetMode(gin.TestMode)
+
+	return InitRouter()
+}
+
+// setupDB 使用内存数据库
+func setupDB(t *testing.T) {
+	t.Helper()
+
+	conn, e := gorm.Open("sqlite3", ":memory:")
+	if e != nil {
+		t.Fatal(e)
+	}
+	t.Cleanup(func() { conn.Close() })
+	setting.DatabaseSetting.TablePrefix = "blog_"
+	models.SetDB(conn)
+	//内存数据库只存在于一个连接中
+	conn.DB().SetMaxOpenConns(1)
+	conn.LogMode(false)
+	e = conn.AutoMigrate(&models.Article{}, &models.ArticleRevision{}, &models.Tag{}, &models.Media{}, &models.MediaTag{}).Error
+	if e != nil {
+		t.Fatal(e)
+	}
+}
+
+// code 返回 JSON 响应中的错误码
+func code(t *testing.T, w *httptest.ResponseRecorder) int {
+	t.Helper()
+
+	var body struct {
+		Code int `json:"code"`
+	}
+	if e := json.Unmarshal(w.Body.Bytes(), &body); e != nil {
+		t.Fatalf("%s: %v", w.Body, e)
+	}
+
+	return body.Code
+}
+
+func put(t *testing.T, key, content string) {
+	t.Helper()
+
+	if e := storage.Default.Put(key, strings.NewReader(content), ""); e != nil {
+		t.Fatal(e)
+	}
+}
+
+func get(r *gin.Engine, url, role string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	if role != "" {
+		token, _, _ := util.GenerateToken("user", role)
+		req.Header.Set("Authorization", token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+// 本地存储的目录不能被列出，导出目录不能直接访问
+func TestStaticFiles(t *testing.T) {
+	r := setupTest(t)
+	put(t, "upload/images/ab/cd/a.jpg", "image")
+	put(t, "poster/poster-1-a.png", "poster")
+	put(t, "export/articles-1-0123456789abcdef0123456789abcdef.csv", "draft")
+
+	tests := []struct {
+		url  string
+		code int
+		body string
+	}{
+		{"/upload/images/ab/cd/a.jpg", http.StatusOK, "image"},
+		{"/poster/poster-1-a.png", http.StatusOK, "poster"},
+		{"/upload/images/", http.StatusNotFound, ""},
+		{"/upload/images/ab/", http.StatusNotFound, ""},
+		{"/poster/", http.StatusNotFound, ""},
+		{"/export/", http.StatusNotFound, ""},
+		{"/export/articles-1-0123456789abcdef0123456789abcdef.csv", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := get(r, tt.url, "")
+		if w.Code != tt.code {
+			t.Errorf("GET %s = %d, want %d", tt.url, w.Code, tt.code)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("GET %s body = %q, want %q", tt.url, w.Body.String(), tt.body)
+		}
+		if strings.Contains(w.Body.String(), "<a href") {
+			t.Errorf("GET %s lists the directory", tt.url)
+		}
+	}
+}
+
+func TestDownloadExport(t *testing.T) {
+	r := setupTest(t)
+	articles := "articles-1-0123456789abcdef0123456789abcdef.csv"
+	tags := "tags-1-0123456789abcdef0123456789abcdef.json"
+	put(t, export.GetExcelPath()+articles, "title\ndraft\n")
+	put(t, export.GetExcelPath()+tags, "[]")
+
+	tests := []struct {
+		name string
+		file string
+		role string
+		code int
+	}{
+		{"anonymous", articles, "", http.StatusUnauthorized},
+		{"author articles", articles, "author", http.StatusOK},
+		{"author tags", tags, "author", http.StatusForbidden},
+		{"admin tags", tags, "admin", http.StatusOK},
+		{"missing", "articles-2-0123456789abcdef0123456789abcdef.csv", "admin", http.StatusNotFound},
+		{"invalid name", "articles.csv", "admin", http.StatusBadRequest},
+		{"unknown prefix", "users-1-0123456789abcdef0123456789abcdef.csv", "admin", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(r, "/api/v1/exports/"+tt.file, tt.role)
+			if w.Code != tt.code {
+				t.Fatalf("GET = %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="`+tt.file+`"` {
+				t.Errorf("Content-Disposition = %q", got)
+			}
+			if tt.file == articles && w.Body.String() != "title\ndraft\n" {
+				t.Errorf("body = %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestExportURL(t *testing.T) {
+	setupTest(t)
+	if got := export.GetExcelFullUrl("tags-1-a.csv"); got != "http://localhost:8080/api/v1/exports/tags-1-a.csv" {
+		t.Errorf("GetExcelFullUrl() = %q", got)
+	}
+}
+
+// 文章不存在时与其他接口一致，返回 200 和错误码
+func TestRevisionArticleNotExist(t *testing.T) {
+	r := setupTest(t)
+	setupDB(t)
+
+	for _, url := range []string{"/api/v1/articles/1/revisions", "/api/v1/articles/1/revisions/1/diff"} {
+		w := get(r, url, "admin")
+		if w.Code != http.StatusOK || code(t, w) != err.ERROR_NOT_EXIST_ARTICLE {
+			t.Errorf("GET %s = %d %s, want 200 and ERROR_NOT_EXIST_ARTICLE", url, w.Code, w.Body)
+		}
+	}
+}
+
+// 站点地图分页超出范围时返回 404，页码格式不正确时返回 400
+func TestSitemapPage(t *testing.T) {
+	r := setupTest(t)
+	setupDB(t)
+
+	tests := []struct {
+		url    string
+		status int
+		code   int
+	}{
+		{"/sitemap.xml", http.StatusOK, 0},
+		//没有拆分时不存在分页
+		{"/sitemaps/1.xml", http.StatusNotFound, err.ERROR_NOT_EXIST_SITEMAP},
+		{"/sitemaps/2.xml", http.StatusNotFound, err.ERROR_NOT_EXIST_SITEMAP},
+		{"/sitemaps/0.xml", http.StatusBadRequest, err.INVALID_PARAMS},
+		{"/sitemaps/a.xml", http.StatusBadRequest, err.INVALID_PARAMS},
+	}
+
+	for _, tt := range tests {
+		w := get(r, tt.url, "")
+		if w.Code != tt.status {
+			t.Errorf("GET %s = %d %s, want %d", tt.url, w.Code, w.Body, tt.status)
+			continue
+		}
+		if tt.code != 0 && code(t, w) != tt.code {
+			t.Errorf("GET %s code = %d, want %d", tt.url, code(t, w), tt.code)
+		}
+	}
+}
+
+// 未引用的文件包含所有用户上传的文件，只有管理员可以列出
+func TestMediaOrphansPermission(t *testing.T) {
+	r := setupTest(t)
+	setupDB(t)
+
+	for role, status := range map[string]int{"admin": http.StatusOK, "editor": http.StatusForbidden, "author": http.StatusForbidden} {
+		if w := get(r, "/api/v1/media/orphans", role); w.Code != status {
+			t.Errorf("GET /api/v1/media/orphans as %s = %d %s, want %d", role, w.Code, w.Body, status)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/setting"
+	"gin-blog/service/article_service"
+	"gin-blog/service/media_service"
+	"time"
+)
+
+func init() {
+	Register("clean_article", func() error {
+		return models.CleanAllArticle(trashDeadline())
+	})
+	Register("clean_tag", func() error {
+		_, err := models.CleanAllTag(trashDeadline())
+		return err
+	})
+	Register("publish_articles", article_service.PublishDue)
+	Register("clean_media", media_service.CleanOrphans)
+}
+
+// 回收站保留期限之前删除的数据才会被彻底清理
+func trashDeadline() int {
+	return int(time.Now().AddDate(0, 0, -setting.AppSetting.TrashRetentionDays).Unix())
+}
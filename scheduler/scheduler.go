@@ -0,0 +1,289 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"gin-blog/models"
+	"gin-blog/pkg/gredis"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"github.com/robfig/cron"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	ErrJobNotFound = errors.New("scheduler: job not found")
+	ErrJobRunning  = errors.New("scheduler: job is already running")
+)
+
+type job struct {
+	name    string
+	spec    string
+	enabled bool
+	run     func() error
+	//本实例中任务正在执行时持有，手动执行和调度执行、上一次调度还没结束的下一次调度都不会同时执行
+	running sync.Mutex
+}
+
+// Info 任务的配置及运行状态
+type Info struct {
+	Name    string         `json:"name"`
+	Spec    string         `json:"spec"`
+	Enabled bool           `json:"enabled"`
+	Next    int64          `json:"next"`
+	Prev    int64          `json:"prev"`
+	LastRun *models.JobRun `json:"last_run"`
+}
+
+var (
+	mu   sync.Mutex
+	jobs = make(map[string]*job)
+	c    *cron.Cron
+)
+
+// Register 注册任务，任务的执行计划在 app.ini 的 [job.<name>] 中配置
+func Register(name string, run func() error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	jobs[name] = &job{name: name, run: run}
+}
+
+// Setup 按配置启动已注册的任务
+func Setup() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	c = cron.New()
+	for _, s := range setting.JobSettings {
+		j, ok := jobs[s.Name]
+		if !ok {
+			logging.Warn("scheduler: job", s.Name, "is configured but not registered")
+			continue
+		}
+
+		j.spec = s.Spec
+		j.enabled = s.Enabled
+		if !setting.SchedulerSetting.Enabled || !j.enabled || j.spec == "" {
+			continue
+		}
+		if err := c.AddJob(j.spec, j); err != nil {
+			logging.Error("scheduler: invalid spec for job", j.name, err)
+		}
+	}
+
+	c.Start()
+}
+
+// Stop 停止调度，已经在执行的任务不会被中断
+func Stop() {
+	if c != nil {
+		c.Stop()
+	}
+}
+
+// Jobs 列出所有已注册的任务
+func Jobs() ([]Info, error) {
+	mu.Lock()
+	infos := make([]Info, 0, len(jobs))
+	for _, j := range jobs {
+		infos = append(infos, Info{Name: j.name, Spec: j.spec, Enabled: j.enabled})
+	}
+	mu.Unlock()
+
+	entries := make(map[string]*cron.Entry)
+	if c != nil {
+		for _, entry := range c.Entries() {
+			if j, ok := entry.Job.(*job); ok {
+				entries[j.name] = entry
+			}
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	for i := range infos {
+		if entry, ok := entries[infos[i].Name]; ok {
+			infos[i].Next = unix(entry.Next)
+			infos[i].Prev = unix(entry.Prev)
+		}
+
+		run, err := models.GetLastJobRun(infos[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		if run.ID > 0 {
+			infos[i].LastRun = run
+		}
+	}
+
+	return infos, nil
+}
+
+// Exists 检查任务是否已注册
+func Exists(name string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, ok := jobs[name]
+	return ok
+}
+
+// Runs 分页获取任务的执行记录，按执行时间倒序
+func Runs(name string, pageNum, pageSize int) ([]models.JobRun, error) {
+	return models.GetJobRuns(pageNum, pageSize, map[string]interface{}{"name": name})
+}
+
+func CountRuns(name string) (int, error) {
+	return models.GetJobRunTotal(map[string]interface{}{"name": name})
+}
+
+// Trigger 立即在后台执行一次任务，任务正在执行时返回 ErrJobRunning
+func Trigger(name string) error {
+	mu.Lock()
+	j, ok := jobs[name]
+	mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+
+	unlock, ok, err := j.lock("")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrJobRunning
+	}
+
+	go func() {
+		defer unlock()
+		j.execute(TriggerManual)
+	}()
+
+	return nil
+}
+
+// Run 实现 cron.Job，由调度器按计划调用
+func (j *job) Run() {
+	//同一时刻被多个实例调度时，只有拿到本次调度锁的实例执行
+	tick := fmt.Sprintf("%d", time.Now().Truncate(time.Second).Unix())
+	unlock, ok, err := j.lock(tick)
+	if err != nil {
+		logging.Error("scheduler: lock job", j.name, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	j.execute(TriggerSchedule)
+}
+
+func (j *job) execute(trigger string) {
+	start := time.Now()
+	err := j.safeRun()
+
+	run := &models.JobRun{
+		Name:      j.name,
+		Host:      hostname(),
+		Trigger:   trigger,
+		StartedOn: int(start.Unix()),
+		Duration:  int(time.Since(start) / time.Millisecond),
+	}
+	if err != nil {
+		run.Error = err.Error()
+		logging.Error("scheduler: job", j.name, "failed:", err)
+	} else {
+		logging.Info("scheduler: job", j.name, "finished in", time.Since(start))
+	}
+
+	if err := models.AddJobRun(run); err != nil {
+		logging.Error("scheduler: save job run", j.name, err)
+	}
+}
+
+func (j *job) safeRun() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return j.run()
+}
+
+// lock 获取任务的执行锁，总是先获取本实例内的锁，开启 DistributedLock 时再获取 Redis 中的锁
+func (j *job) lock(tick string) (func(), bool, error) {
+	if !j.running.TryLock() {
+		return nil, false, nil
+	}
+	if !setting.SchedulerSetting.DistributedLock {
+		return j.running.Unlock, true, nil
+	}
+
+	unlock, ok, err := j.lockRedis(tick)
+	if err != nil || !ok {
+		j.running.Unlock()
+		return nil, ok, err
+	}
+
+	return func() {
+		unlock()
+		j.running.Unlock()
+	}, true, nil
+}
+
+// lockRedis 获取多个实例之间的执行锁，tick 不为空时额外获取本次调度的锁，该锁不主动释放，等待过期，
+// 保证各实例时钟有偏差时同一次调度也只会执行一次
+func (j *job) lockRedis(tick string) (func(), bool, error) {
+	ttl := int(setting.SchedulerSetting.LockTTL / time.Second)
+	token, err := util.RandomToken(16)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if tick != "" {
+		ok, err := gredis.Lock("LOCK_JOB_"+j.name+"_"+tick, token, ttl)
+		if err != nil || !ok {
+			return nil, ok, err
+		}
+	}
+
+	key := "LOCK_JOB_" + j.name
+	ok, err := gredis.Lock(key, token, ttl)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	return func() {
+		if err := gredis.Unlock(key, token); err != nil {
+			logging.Warn("scheduler: unlock job", j.name, err)
+		}
+	}, true, nil
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+
+	return name
+}
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
@@ -1,16 +1,36 @@
 package article_service
 
 import (
-	"encoding/json"
 	"gin-blog/models"
-	"gin-blog/pkg/gredis"
+	"gin-blog/pkg/gcache"
 	"gin-blog/pkg/logging"
+	"gin-blog/pkg/markdown"
 	"gin-blog/service/cache_service"
+	"github.com/jinzhu/gorm"
+	"golang.org/x/sync/singleflight"
+	"time"
 )
 
+const (
+	cacheTTL = time.Hour
+	//不存在的文章也会缓存一小段时间，避免无效ID的请求每次都查询数据库
+	notFoundTTL = time.Minute
+)
+
+var (
+	store gcache.Cache = gcache.NewNone()
+	//同一个缓存key同一时刻只有一个请求查询数据库，其余请求等待并共享结果
+	group singleflight.Group
+)
+
+// SetCache 设置文章缓存使用的后端，未设置时不缓存
+func SetCache(c gcache.Cache) {
+	store = c
+}
+
 type Article struct {
 	ID            int
-	TagID         int
+	TagIDs        []int
 	Title         string
 	Desc          string
 	Content       string
@@ -18,63 +38,116 @@ type Article struct {
 	State         int
 	CreatedBy     string
 	ModifiedBy    string
+	PublishAt     int
+	UnpublishAt   int
+	//导入文章时使用原来的发布日期，为0时使用当前时间
+	CreatedOn int
+
+	//筛选文章时是否要求同时包含TagIDs中的所有标签
+	MatchAllTags bool
+	//为true时只返回已发布的文章，供没有写权限的读者使用
+	PublishedOnly bool
 
 	PageNum  int
 	PageSize int
 }
 
 func (a *Article) Add() error {
+	a.normalizeSchedule()
 	article := map[string]interface{}{
-		"tag_id":          a.TagID,
+		"tag_ids":         a.TagIDs,
 		"title":           a.Title,
 		"desc":            a.Desc,
 		"content":         a.Content,
 		"created_by":      a.CreatedBy,
 		"cover_image_url": a.CoverImageUrl,
 		"state":           a.State,
+		"publish_at":      a.PublishAt,
+		"unpublish_at":    a.UnpublishAt,
+		"created_on":      a.CreatedOn,
 	}
 
-	if err := models.AddArticle(article); err != nil {
+	id, err := models.AddArticle(article)
+	if err != nil {
 		return err
 	}
 
+	a.ID = id
+
+	a.afterWrite()
 	return nil
 }
 
 func (a *Article) Edit() error {
-	return models.EditArticle(a.ID, map[string]interface{}{
-		"tag_id":          a.TagID,
+	a.normalizeSchedule()
+	err := models.EditArticle(a.ID, map[string]interface{}{
+		"tag_ids":         a.TagIDs,
 		"title":           a.Title,
 		"desc":            a.Desc,
 		"content":         a.Content,
 		"cover_image_url": a.CoverImageUrl,
 		"state":           a.State,
+		"publish_at":      a.PublishAt,
+		"unpublish_at":    a.UnpublishAt,
 		"modified_by":     a.ModifiedBy,
 	})
+	if err != nil {
+		return err
+	}
+
+	a.afterWrite()
+	return nil
 }
 
+// Get 获取文章详情，文章不存在时返回 nil，PublishedOnly 时未发布的文章也返回 nil
 func (a *Article) Get() (*models.Article, error) {
+	article, err := a.get()
+	if err != nil || article == nil {
+		return article, err
+	}
+
+	//详情缓存不区分是否发布，每次读取时按当前时间判断
+	if a.PublishedOnly && !article.IsPublished(int(time.Now().Unix())) {
+		return nil, nil
+	}
+
+	return article, nil
+}
+
+func (a *Article) get() (*models.Article, error) {
 	var cacheArticle *models.Article
 
 	cache := cache_service.Article{ID: a.ID}
-	key := cache.GetArticleKey()
-	if gredis.Exists(key) {
-		data, err := gredis.Get(key)
+	key := cache.GetArticleKey(store)
+	if err := gcache.GetObject(store, key, &cacheArticle); err == nil {
+		return cacheArticle, nil
+	} else if err != gcache.ErrNotFound {
+		logging.Info(err)
+	}
+
+	v, err, _ := group.Do(key, func() (interface{}, error) {
+		article, err := models.GetArticle(a.ID)
 		if err != nil {
-			logging.Info(err)
-		} else {
-			json.Unmarshal(data, &cacheArticle)
-			return cacheArticle, nil
+			return nil, err
+		}
+		if article.ID == 0 {
+			gcache.SetObject(store, key, nil, gcache.Jitter(notFoundTTL))
+			return (*models.Article)(nil), nil
 		}
-	}
 
-	article, err := models.GetArticle(a.ID)
+		//渲染结果随详情一起缓存，内容修改时详情缓存会被清理
+		if article.ContentHtml, article.Toc, err = markdown.Render(article.Content); err != nil {
+			return nil, err
+		}
+
+		gcache.SetObject(store, key, article, gcache.Jitter(cacheTTL))
+		return article, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	gredis.Set(key, article, 3600)
-	return article, nil
+	return v.(*models.Article), nil
 }
 
 func (a *Article) GetAll() ([]*models.Article, error) {
@@ -83,34 +156,65 @@ func (a *Article) GetAll() ([]*models.Article, error) {
 	)
 
 	cache := cache_service.Article{
-		TagID: a.TagID,
-		State: a.State,
+		TagIDs:    a.TagIDs,
+		MatchAll:  a.MatchAllTags,
+		State:     a.State,
+		Published: a.PublishedOnly,
 
 		PageNum:  a.PageNum,
 		PageSize: a.PageSize,
 	}
-	key := cache.GetArticlesKey()
-	if gredis.Exists(key) {
-		data, err := gredis.Get(key)
-		if err != nil {
-			logging.Info(err)
-		} else {
-			json.Unmarshal(data, &cacheArticles)
-			return cacheArticles, nil
-		}
+	key := cache.GetArticlesKey(store)
+	if err := gcache.GetObject(store, key, &cacheArticles); err == nil {
+		return cacheArticles, nil
+	} else if err != gcache.ErrNotFound {
+		logging.Info(err)
 	}
 
-	articles, err := models.GetArticles(a.PageNum, a.PageSize, a.getMaps())
+	articles, err := models.GetArticles(a.PageNum, a.PageSize, a.getMaps(), a.scopes()...)
 	if err != nil {
 		return nil, err
 	}
 
-	gredis.Set(key, articles, 3600)
+	gcache.SetObject(store, key, articles, gcache.Jitter(cacheTTL))
 	return articles, nil
 }
 
 func (a *Article) Delete() error {
-	return models.DeleteArticle(a.ID)
+	if err := models.DeleteArticle(a.ID); err != nil {
+		return err
+	}
+
+	a.afterWrite()
+	return nil
+}
+
+// GetDeleted 获取回收站中的文章
+func (a *Article) GetDeleted() ([]*models.Article, error) {
+	return models.GetDeletedArticles(a.PageNum, a.PageSize)
+}
+
+func (a *Article) CountDeleted() (int, error) {
+	return models.GetDeletedArticleTotal()
+}
+
+func (a *Article) ExistDeletedByID() (bool, error) {
+	return models.ExistDeletedArticleByID(a.ID)
+}
+
+// Restore 从回收站恢复文章
+func (a *Article) Restore() error {
+	if err := models.RestoreArticle(a.ID); err != nil {
+		return err
+	}
+
+	a.afterWrite()
+	return nil
+}
+
+// Purge 彻底删除回收站中的文章
+func (a *Article) Purge() error {
+	return models.PurgeArticle(a.ID)
 }
 
 func (a *Article) ExistByID() (bool, error) {
@@ -118,18 +222,94 @@ func (a *Article) ExistByID() (bool, error) {
 }
 
 func (a *Article) Count() (int, error) {
-	return models.GetArticleTotal(a.getMaps())
+	var count int
+
+	cache := cache_service.Article{
+		TagIDs:    a.TagIDs,
+		MatchAll:  a.MatchAllTags,
+		State:     a.State,
+		Published: a.PublishedOnly,
+	}
+	key := cache.GetCountKey(store)
+	if err := gcache.GetObject(store, key, &count); err == nil {
+		return count, nil
+	} else if err != gcache.ErrNotFound {
+		logging.Info(err)
+	}
+
+	count, err := models.GetArticleTotal(a.getMaps(), a.scopes()...)
+	if err != nil {
+		return 0, err
+	}
+
+	gcache.SetObject(store, key, count, gcache.Jitter(cacheTTL))
+	return count, nil
+}
+
+// PublishDue 发布、下线到达设定时间的文章，供定时任务调用
+func PublishDue() error {
+	now := int(time.Now().Unix())
+	published, err := models.PublishDueArticles(now)
+	if err != nil {
+		return err
+	}
+	unpublished, err := models.UnpublishDueArticles(now)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range append(published, unpublished...) {
+		article := Article{ID: id}
+		article.afterWrite()
+	}
+	if len(published)+len(unpublished) > 0 {
+		logging.Info("published", len(published), "articles, unpublished", len(unpublished), "articles")
+	}
+
+	return nil
 }
 
 func (a *Article) getMaps() map[string]interface{} {
 	maps := make(map[string]interface{})
 	maps["deleted_on"] = 0
-	if a.State != -1 {
+	//只看已发布的文章时由Published决定状态
+	if a.State != -1 && !a.PublishedOnly {
 		maps["state"] = a.State
 	}
-	if a.TagID != -1 {
-		maps["tag_id"] = a.TagID
-	}
 
 	return maps
 }
+
+func (a *Article) scopes() []func(*gorm.DB) *gorm.DB {
+	scopes := []func(*gorm.DB) *gorm.DB{models.WithTagIDs(a.TagIDs, a.MatchAllTags)}
+	if a.PublishedOnly {
+		scopes = append(scopes, models.Published(int(time.Now().Unix())))
+	}
+
+	return scopes
+}
+
+// 设置了未来的发布时间时先保存为草稿，到时间后由定时任务发布；发布时间已过则直接发布
+func (a *Article) normalizeSchedule() {
+	if a.PublishAt <= 0 {
+		a.PublishAt = 0
+		return
+	}
+
+	if a.PublishAt > int(time.Now().Unix()) {
+		a.State = 0
+	} else {
+		a.State = 1
+		a.PublishAt = 0
+	}
+}
+
+// 写操作成功后清理缓存并同步搜索索引，失败只记录日志，不影响已经成功的写操作
+func (a *Article) afterWrite() {
+	cache := cache_service.Article{ID: a.ID}
+	if err := cache.Clear(store); err != nil {
+		logging.Warn(err)
+	}
+
+	reindex(a.ID)
+}
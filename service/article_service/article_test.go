@@ -0,0 +1,304 @@
+package article_service
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/gcache"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/service/tag_service"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// setupTest 使用内存数据库和内存缓存，文章和标签共用同一个缓存
+func setupTest(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	//日志路径相对于当前目录
+	wd, _ := os.Getwd()
+	dir, err := filepath.Rel(wd, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setting.AppSetting.RuntimeRootPath = dir + "/"
+	setting.AppSetting.LogSavePath = ""
+	setting.AppSetting.LogSaveName = "log"
+	setting.AppSetting.LogFileExt = "log"
+	setting.AppSetting.TimeFormat = "20060102"
+	logging.Setup()
+
+	conn, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	setting.DatabaseSetting.TablePrefix = "blog_"
+	models.SetDB(conn)
+	//内存数据库只存在于一个连接中
+	conn.DB().SetMaxOpenConns(1)
+	conn.LogMode(false)
+	if err := conn.AutoMigrate(&models.Article{}, &models.Tag{}, &models.ArticleRevision{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	store := gcache.NewMemory(0)
+	SetCache(store)
+	tag_service.SetCache(store)
+	t.Cleanup(func() {
+		SetCache(gcache.NewNone())
+		tag_service.SetCache(gcache.NewNone())
+	})
+
+	return conn
+}
+
+func addTag(t *testing.T, name string) int {
+	t.Helper()
+
+	if err := models.AddTag(name, 1, "test"); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := models.GetTagsByNames([]string{name})
+	if err != nil || len(tags) != 1 {
+		t.Fatal(tags, err)
+	}
+
+	return tags[0].ID
+}
+
+func addArticle(t *testing.T, title string, tagIDs []int) *Article {
+	t.Helper()
+
+	article := &Article{
+		TagIDs:    tagIDs,
+		Title:     title,
+		Desc:      title,
+		Content:   title,
+		CreatedBy: "test",
+		State:     1,
+	}
+	if err := article.Add(); err != nil {
+		t.Fatal(err)
+	}
+
+	return article
+}
+
+func listTitles(t *testing.T) []string {
+	t.Helper()
+
+	articles, err := (&Article{State: -1, PageSize: 10}).GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := make([]string, 0, len(articles))
+	for _, article := range articles {
+		titles = append(titles, article.Title)
+	}
+
+	return titles
+}
+
+func countArticles(t *testing.T) int {
+	t.Helper()
+
+	count, err := (&Article{State: -1}).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+func TestArticleCacheFreshAfterWrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		write      func(t *testing.T, article *Article)
+		wantTitles []string
+		wantCount  int
+		//写操作后详情的标题，为空时文章应不存在
+		wantDetail string
+	}{
+		{
+			name: "edit",
+			write: func(t *testing.T, article *Article) {
+				article.Title = "edited"
+				if err := article.Edit(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantTitles: []string{"first", "edited"},
+			wantCount:  2,
+			wantDetail: "edited",
+		},
+		{
+			name: "delete",
+			write: func(t *testing.T, article *Article) {
+				if err := article.Delete(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantTitles: []string{"first"},
+			wantCount:  1,
+		},
+		{
+			name: "add",
+			write: func(t *testing.T, article *Article) {
+				addArticle(t, "third", article.TagIDs)
+			},
+			wantTitles: []string{"first", "second", "third"},
+			wantCount:  3,
+			wantDetail: "second",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			tagIDs := []int{addTag(t, "go")}
+			addArticle(t, "first", tagIDs)
+			article := addArticle(t, "second", tagIDs)
+
+			//先读取一次，让列表、数量和详情都进入缓存
+			listTitles(t)
+			countArticles(t)
+			if _, err := (&Article{ID: article.ID}).Get(); err != nil {
+				t.Fatal(err)
+			}
+
+			tt.write(t, article)
+
+			if got := listTitles(t); !equalStrings(got, tt.wantTitles) {
+				t.Errorf("GetAll() = %v, want %v", got, tt.wantTitles)
+			}
+			if got := countArticles(t); got != tt.wantCount {
+				t.Errorf("Count() = %d, want %d", got, tt.wantCount)
+			}
+			detail, err := (&Article{ID: article.ID}).Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantDetail == "" && detail != nil {
+				t.Errorf("Get() = %q, want nil", detail.Title)
+			}
+			if tt.wantDetail != "" && (detail == nil || detail.Title != tt.wantDetail) {
+				t.Errorf("Get() = %+v, want title %q", detail, tt.wantDetail)
+			}
+		})
+	}
+}
+
+// 文章详情和列表中包含标签，修改标签后两者都要重新读取
+func TestArticleCacheFreshAfterTagEdit(t *testing.T) {
+	setupTest(t)
+	tagID := addTag(t, "go")
+	article := addArticle(t, "first", []int{tagID})
+
+	if _, err := (&Article{ID: article.ID}).Get(); err != nil {
+		t.Fatal(err)
+	}
+	listTitles(t)
+
+	tag := tag_service.Tag{ID: tagID, Name: "golang", ModifiedBy: "test", State: -1}
+	if err := tag.Edit(); err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err := (&Article{ID: article.ID}).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Tags) != 1 || detail.Tags[0].Name != "golang" {
+		t.Errorf("Get().Tags = %+v, want golang", detail.Tags)
+	}
+
+	articles, err := (&Article{State: -1, PageSize: 10}).GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(articles) != 1 || len(articles[0].Tags) != 1 || articles[0].Tags[0].Name != "golang" {
+		t.Errorf("GetAll() tags = %+v, want golang", articles)
+	}
+}
+
+func TestArticleTagFilter(t *testing.T) {
+	setupTest(t)
+	goID, webID, dbID := addTag(t, "go"), addTag(t, "web"), addTag(t, "db")
+	addArticle(t, "go", []int{goID})
+	addArticle(t, "go web", []int{goID, webID})
+	addArticle(t, "web", []int{webID})
+	addArticle(t, "none", nil)
+
+	tests := []struct {
+		tagIDs   []int
+		matchAll bool
+		want     []string
+	}{
+		{[]int{goID}, false, []string{"go", "go web"}},
+		{[]int{webID}, false, []string{"go web", "web"}},
+		{[]int{goID, webID}, false, []string{"go", "go web", "web"}},
+		{[]int{goID, webID}, true, []string{"go web"}},
+		{[]int{dbID}, false, []string{}},
+	}
+	for _, tt := range tests {
+		article := &Article{TagIDs: tt.tagIDs, MatchAllTags: tt.matchAll, State: -1, PageSize: 10}
+		articles, err := article.GetAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		titles := make([]string, 0, len(articles))
+		for _, a := range articles {
+			titles = append(titles, a.Title)
+		}
+		sort.Strings(titles)
+		if !equalStrings(titles, tt.want) {
+			t.Errorf("GetAll(tags=%v, all=%v) = %v, want %v", tt.tagIDs, tt.matchAll, titles, tt.want)
+		}
+		if count, err := article.Count(); err != nil || count != len(tt.want) {
+			t.Errorf("Count(tags=%v, all=%v) = %d, %v, want %d", tt.tagIDs, tt.matchAll, count, err, len(tt.want))
+		}
+	}
+}
+
+// 清空回收站只删除被删除文章的标签关联
+func TestCleanAllArticleKeepsTags(t *testing.T) {
+	setupTest(t)
+	tagID := addTag(t, "go")
+	deleted := addArticle(t, "deleted", []int{tagID})
+	kept := []*Article{addArticle(t, "kept 1", []int{tagID}), addArticle(t, "kept 2", []int{tagID})}
+
+	if err := deleted.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := models.CleanAllArticle(int(time.Now().Unix()) + 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, article := range kept {
+		detail, err := (&Article{ID: article.ID}).Get()
+		if err != nil || detail == nil {
+			t.Fatal(detail, err)
+		}
+		if len(detail.Tags) != 1 || detail.Tags[0].ID != tagID {
+			t.Errorf("%s tags = %+v, want go", article.Title, detail.Tags)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,76 @@
+package article_service
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/export"
+	"strings"
+)
+
+// 导出时每次从数据库读取的文章数量
+const exportBatchSize = 500
+
+// Export 按 GetAll 相同的筛选条件导出全部文章，返回导出的文件名
+func (a *Article) Export(format string) (string, error) {
+	table := export.Table{
+		Name: "文章信息",
+		Columns: []export.Column{
+			{Key: "id", Title: "ID"},
+			{Key: "title", Title: "标题"},
+			{Key: "desc", Title: "简述"},
+			{Key: "tags", Title: "标签"},
+			{Key: "cover_image_url", Title: "封面图片"},
+			{Key: "state", Title: "状态"},
+			{Key: "publish_at", Title: "定时发布时间"},
+			{Key: "unpublish_at", Title: "定时下线时间"},
+			{Key: "created_by", Title: "创建人"},
+			{Key: "created_on", Title: "创建时间"},
+			{Key: "modified_by", Title: "修改人"},
+			{Key: "modified_on", Title: "修改时间"},
+			{Key: "content", Title: "内容"},
+		},
+	}
+
+	scopes := append(a.scopes(), orderByID)
+	for offset := 0; ; offset += exportBatchSize {
+		articles, err := models.GetArticles(offset, exportBatchSize, a.getMaps(), scopes...)
+		if err != nil {
+			return "", err
+		}
+		for _, article := range articles {
+			table.Rows = append(table.Rows, exportRow(article))
+		}
+		if len(articles) < exportBatchSize {
+			break
+		}
+	}
+
+	return table.Save("articles", format)
+}
+
+func exportRow(article *models.Article) []interface{} {
+	tags := make([]string, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		tags = append(tags, tag.Name)
+	}
+
+	state := "草稿"
+	if article.State == 1 {
+		state = "已发布"
+	}
+
+	return []interface{}{
+		article.ID,
+		article.Title,
+		article.Desc,
+		strings.Join(tags, ","),
+		article.CoverImageUrl,
+		state,
+		export.FormatTime(article.PublishAt),
+		export.FormatTime(article.UnpublishAt),
+		article.CreatedBy,
+		export.FormatTime(article.CreatedOn),
+		article.ModifiedBy,
+		export.FormatTime(article.ModifiedOn),
+		article.Content,
+	}
+}
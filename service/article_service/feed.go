@@ -0,0 +1,146 @@
+package article_service
+
+import (
+	"errors"
+	"gin-blog/models"
+	"gin-blog/pkg/feed"
+	"gin-blog/pkg/gcache"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/markdown"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/cache_service"
+	"github.com/jinzhu/gorm"
+	"strconv"
+	"time"
+)
+
+const (
+	FeedRSS  = "rss"
+	FeedAtom = "atom"
+	FeedJSON = "json"
+)
+
+var errUnknownFeedFormat = errors.New("unknown feed format")
+
+var feedBuilders = map[string]func(*feed.Feed) ([]byte, error){
+	FeedRSS:  feed.RSS,
+	FeedAtom: feed.Atom,
+	FeedJSON: feed.JSON,
+}
+
+// Feed 最新已发布文章的订阅，TagID 大于0时只包含该标签下的文章
+type Feed struct {
+	Format string
+	TagID  int
+}
+
+// Get 返回生成的订阅内容，标签不存在或未启用时返回 nil
+func (f *Feed) Get() ([]byte, error) {
+	cache := cache_service.Feed{Format: f.Format, TagID: f.TagID}
+	key := cache.GetFeedKey(store)
+	if data, err := store.Get(key); err == nil {
+		return data, nil
+	} else if err != gcache.ErrNotFound {
+		logging.Info(err)
+	}
+
+	v, err, _ := group.Do(key, func() (interface{}, error) {
+		data, err := f.build()
+		if err != nil || data == nil {
+			return data, err
+		}
+
+		//定时发布的文章由定时任务修改状态，同时更新文章缓存的版本号，订阅缓存也随之失效
+		if err := store.Set(key, data, gcache.Jitter(cacheTTL)); err != nil {
+			logging.Info(err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]byte), nil
+}
+
+func (f *Feed) build() ([]byte, error) {
+	build, ok := feedBuilders[f.Format]
+	if !ok {
+		return nil, errUnknownFeedFormat
+	}
+
+	result := &feed.Feed{
+		Title:       setting.SiteSetting.Title,
+		Link:        util.AbsoluteURL("/"),
+		Description: setting.SiteSetting.Description,
+	}
+	path := "/"
+	if f.TagID > 0 {
+		tag, err := models.GetTag(f.TagID)
+		if err != nil {
+			return nil, err
+		}
+		if tag.ID == 0 || tag.State != 1 {
+			return nil, nil
+		}
+
+		result.Title += " - " + tag.Name
+		result.Link = util.TagURL(tag.ID)
+		path = "/tags/" + strconv.Itoa(tag.ID) + "/"
+	}
+	result.RSSLink = util.AbsoluteURL(path + "feed.xml")
+	result.AtomLink = util.AbsoluteURL(path + "atom.xml")
+	result.JSONLink = util.AbsoluteURL(path + "feed.json")
+
+	articles, err := models.GetArticles(0, setting.SiteSetting.FeedSize, map[string]interface{}{"deleted_on": 0},
+		models.WithTagIDs(tagIDs(f.TagID), false), models.Published(int(time.Now().Unix())), latestFirst)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, article := range articles {
+		content, _, err := markdown.Render(article.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		item := &feed.Item{
+			ID:          util.ArticleURL(article.ID),
+			Title:       article.Title,
+			Link:        util.ArticleURL(article.ID),
+			Summary:     article.Desc,
+			ContentHTML: content,
+			Image:       util.AbsoluteURL(article.CoverImageUrl),
+			Published:   time.Unix(int64(article.CreatedOn), 0),
+			Updated:     time.Unix(int64(article.ModifiedOn), 0),
+		}
+		for _, tag := range article.Tags {
+			if tag.State == 1 {
+				item.Tags = append(item.Tags, tag.Name)
+			}
+		}
+		if item.Updated.After(result.Updated) {
+			result.Updated = item.Updated
+		}
+		result.Items = append(result.Items, item)
+	}
+	if result.Updated.IsZero() {
+		result.Updated = time.Now()
+	}
+
+	return build(result)
+}
+
+// latestFirst 订阅按创建时间倒序，只取最新的文章
+func latestFirst(db *gorm.DB) *gorm.DB {
+	return db.Order("created_on DESC, id DESC")
+}
+
+func tagIDs(tagID int) []int {
+	if tagID > 0 {
+		return []int{tagID}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package article_service
+
+import (
+	"bytes"
+	"flag"
+	"gin-blog/models"
+	"gin-blog/pkg/setting"
+	"github.com/jinzhu/gorm"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// golden 与 testdata 中的文件比较，-update 时改为写入该文件
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	file := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(file, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match the golden file:\n%s\nwant\n%s", name, got, want)
+	}
+}
+
+// setupSiteTest 使用固定的站点配置和时区，生成的内容与时间无关
+func setupSiteTest(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	conn := setupTest(t)
+	app, site, local := *setting.AppSetting, *setting.SiteSetting, time.Local
+	t.Cleanup(func() {
+		*setting.AppSetting = app
+		*setting.SiteSetting = site
+		time.Local = local
+	})
+	setting.AppSetting.PrefixUrl = "http://localhost:8080"
+	setting.SiteSetting.Title = "gin-blog"
+	setting.SiteSetting.Description = "使用gin开发的个人博客"
+	setting.SiteSetting.FeedSize = 2
+	setting.SiteSetting.ArticlePath = ""
+	setting.SiteSetting.TagPath = ""
+	time.Local = time.FixedZone("CST", 8*3600)
+
+	return conn
+}
+
+// setTimes 修改文章的创建和修改时间
+func setTimes(t *testing.T, conn *gorm.DB, id int, createdOn, modifiedOn string) {
+	t.Helper()
+
+	parse := func(value string) int64 {
+		date, err := time.ParseInLocation("2006-01-02 15:04:05", value, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return date.Unix()
+	}
+	err := conn.Model(&models.Article{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"created_on": parse(createdOn), "modified_on": parse(modifiedOn)}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addFeedArticles 添加订阅使用的文章，返回启用和未启用的标签ID
+func addFeedArticles(t *testing.T, conn *gorm.DB) (int, int) {
+	t.Helper()
+
+	tagID := addTag(t, "Go")
+	if err := models.AddTag("hidden", 0, "test"); err != nil {
+		t.Fatal(err)
+	}
+	hidden, err := models.GetTagsByNames([]string{"hidden"})
+	if err != nil || len(hidden) != 1 {
+		t.Fatal(hidden, err)
+	}
+
+	articles := []struct {
+		article    *Article
+		createdOn  string
+		modifiedOn string
+	}{
+		{&Article{Title: "第一篇", Desc: "简介", Content: "正文", TagIDs: []int{tagID}, State: 1}, "2023-01-02 03:04:05", "2023-01-02 03:04:05"},
+		{
+			&Article{
+				Title:         "第二篇 <文章> & \"引号\"",
+				Desc:          "带封面",
+				Content:       "## 标题\n\n<script>alert(1)</script>\n\n**粗体** [链接](javascript:alert(1))\n",
+				CoverImageUrl: "/upload/images/ab/cd/a.png",
+				TagIDs:        []int{tagID, hidden[0].ID},
+				State:         1,
+			},
+			"2023-03-02 01:00:00", "2023-03-04 05:06:07",
+		},
+		//超过 FeedSize 的旧文章
+		{&Article{Title: "旧文章", Content: "旧", State: 1}, "2022-01-01 00:00:00", "2022-01-01 00:00:00"},
+		//草稿和定时发布的文章不出现在订阅中
+		{&Article{Title: "草稿", Content: "草稿", TagIDs: []int{tagID}, State: 0}, "2024-01-01 00:00:00", "2024-01-01 00:00:00"},
+		{&Article{Title: "定时", Content: "定时", TagIDs: []int{tagID}, PublishAt: int(time.Now().Add(time.Hour).Unix())}, "2024-01-01 00:00:00", "2024-01-01 00:00:00"},
+	}
+	for _, a := range articles {
+		a.article.CreatedBy = "test"
+		if err := a.article.Add(); err != nil {
+			t.Fatal(err)
+		}
+		setTimes(t, conn, a.article.ID, a.createdOn, a.modifiedOn)
+	}
+
+	return tagID, hidden[0].ID
+}
+
+func TestFeedGolden(t *testing.T) {
+	conn := setupSiteTest(t)
+	tagID, _ := addFeedArticles(t, conn)
+
+	tests := []struct {
+		golden string
+		feed   Feed
+	}{
+		{"feed.xml", Feed{Format: FeedRSS}},
+		{"atom.xml", Feed{Format: FeedAtom}},
+		{"feed.json", Feed{Format: FeedJSON}},
+		{"tag_feed.xml", Feed{Format: FeedRSS, TagID: tagID}},
+		{"tag_atom.xml", Feed{Format: FeedAtom, TagID: tagID}},
+		{"tag_feed.json", Feed{Format: FeedJSON, TagID: tagID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			data, err := tt.feed.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden(t, tt.golden, data)
+		})
+	}
+}
+
+func TestFeedNotExist(t *testing.T) {
+	conn := setupSiteTest(t)
+	_, hiddenID := addFeedArticles(t, conn)
+
+	for _, tagID := range []int{hiddenID, 100} {
+		if data, err := (&Feed{Format: FeedRSS, TagID: tagID}).Get(); err != nil || data != nil {
+			t.Errorf("tag %d feed = %s, %v, want nil", tagID, data, err)
+		}
+	}
+	if _, err := (&Feed{Format: "xml"}).Get(); err != errUnknownFeedFormat {
+		t.Errorf("Get() = %v, want errUnknownFeedFormat", err)
+	}
+}
+
+// [site] ArticlePath、TagPath 修改订阅中的链接
+func TestFeedPaths(t *testing.T) {
+	conn := setupSiteTest(t)
+	tagID, _ := addFeedArticles(t, conn)
+	setting.SiteSetting.ArticlePath = "/posts/{id}.html"
+	setting.SiteSetting.TagPath = "https://blog.example.com/tags/{id}/"
+
+	data, err := (&Feed{Format: FeedJSON, TagID: tagID}).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"home_page_url":"https://blog.example.com/tags/1/"`, `"url":"http://localhost:8080/posts/2.html"`} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("feed does not contain %s:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package article_service
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"gin-blog/models"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/markdown"
+	"gin-blog/service/tag_service"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	//压缩包中最多导入的文件数量
+	maxImportFiles = 5000
+	//单个 Markdown 文件的最大字节数，解压时按实际读取的大小判断
+	maxImportFileSize = 1 << 20
+	//front matter 中没有 description 时从正文截取的简述长度
+	importDescSize = 120
+)
+
+// ErrInvalidImportZip 上传的文件不是 zip 压缩包或其中的文件过多
+var ErrInvalidImportZip = errors.New("invalid import zip")
+
+// Jekyll 的文件名以日期开头，如 2019-01-02-hello-world.md
+var datedFilename = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+
+// front matter 中的日期格式，Hexo 和 Jekyll 的日期都按本地时间解析
+var importDateLayouts = []string{
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// MarkdownImportReport 导入结果，每个 Markdown 文件一条记录
+type MarkdownImportReport struct {
+	Created []MarkdownImportFile `json:"created"`
+	Skipped []MarkdownImportFile `json:"skipped"`
+	Failed  []MarkdownImportFile `json:"failed"`
+}
+
+// MarkdownImportFile 压缩包中的一个文件，导入成功时 ID 为新文章的ID
+type MarkdownImportFile struct {
+	File   string `json:"file"`
+	ID     int    `json:"id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Hexo、Jekyll 常用的 front matter 字段，同一含义的多个字段按顺序取第一个非空值
+type frontMatter struct {
+	Title       string    `yaml:"title"`
+	Date        yaml.Node `yaml:"date"`
+	Tags        yaml.Node `yaml:"tags"`
+	Description string    `yaml:"description"`
+	Excerpt     string    `yaml:"excerpt"`
+	Cover       string    `yaml:"cover"`
+	Image       string    `yaml:"image"`
+	Draft       bool      `yaml:"draft"`
+	Published   *bool     `yaml:"published"`
+}
+
+// ImportMarkdown 从 Markdown 文件的 zip 压缩包导入文章，addTags 为 true 时 front matter 中不存在的标签自动创建，
+// 否则包含不存在的标签的文件导入失败，没有管理标签权限的用户不能通过导入创建标签。
+// 标题与已有文章相同的文件会被跳过，所以可以重复导入同一个压缩包
+func ImportMarkdown(r io.ReaderAt, size int64, createdBy string, addTags bool) (*MarkdownImportReport, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportZip, err)
+	}
+
+	var files []*zip.File
+	for _, f := range archive.File {
+		name := path.Base(f.Name)
+		ext := strings.ToLower(path.Ext(name))
+		//跳过目录、macOS 打包时附带的元数据和隐藏文件
+		if f.FileInfo().IsDir() || strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(name, ".") ||
+			(ext != ".md" && ext != ".markdown") {
+			continue
+		}
+		files = append(files, f)
+	}
+	if len(files) > maxImportFiles {
+		return nil, fmt.Errorf("%w: more than %d markdown files", ErrInvalidImportZip, maxImportFiles)
+	}
+
+	report := &MarkdownImportReport{
+		Created: []MarkdownImportFile{},
+		Skipped: []MarkdownImportFile{},
+		Failed:  []MarkdownImportFile{},
+	}
+	for _, f := range files {
+		result := MarkdownImportFile{File: f.Name}
+
+		post, reason := parseMarkdownFile(f)
+		if post != nil {
+			result.Title = post.article.Title
+		}
+		if reason != "" {
+			result.Reason = reason
+			report.Failed = append(report.Failed, result)
+			continue
+		}
+
+		skipped, err := importPost(post, createdBy, addTags)
+		var unknown *unknownTagsError
+		if errors.As(err, &unknown) {
+			result.Reason = "标签不存在：" + strings.Join(unknown.names, "、")
+			report.Failed = append(report.Failed, result)
+			continue
+		}
+		if err != nil {
+			logging.Warn(f.Name, err)
+			result.Reason = "保存失败"
+			report.Failed = append(report.Failed, result)
+			continue
+		}
+		if skipped {
+			result.Reason = "标题与已有文章相同"
+			report.Skipped = append(report.Skipped, result)
+			continue
+		}
+
+		result.ID = post.article.ID
+		report.Created = append(report.Created, result)
+	}
+
+	return report, nil
+}
+
+// unknownTagsError 不能创建标签时文件中包含不存在的标签
+type unknownTagsError struct {
+	names []string
+}
+
+func (e *unknownTagsError) Error() string {
+	return "unknown tags: " + strings.Join(e.names, ", ")
+}
+
+// importPost 保存一篇文章，标题与已有文章相同时跳过
+func importPost(post *markdownPost, createdBy string, addTags bool) (bool, error) {
+	exists, err := models.ExistArticleByTitle(post.article.Title)
+	if err != nil || exists {
+		return exists, err
+	}
+
+	tagService := tag_service.Tag{CreatedBy: createdBy}
+	if !addTags {
+		missing, err := tagService.MissingNames(post.tags)
+		if err != nil {
+			return false, err
+		}
+		if len(missing) > 0 {
+			return false, &unknownTagsError{names: missing}
+		}
+	}
+	if post.article.TagIDs, err = tagService.GetOrAddByNames(post.tags); err != nil {
+		return false, err
+	}
+	post.article.CreatedBy = createdBy
+
+	return false, post.article.Add()
+}
+
+type markdownPost struct {
+	article *Article
+	tags    []string
+}
+
+// 读取并解析一个文件，文件内容不符合要求时返回失败原因
+func parseMarkdownFile(f *zip.File) (*markdownPost, string) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, "无法解压：" + err.Error()
+	}
+	defer rc.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(rc, maxImportFileSize+1))
+	if err != nil {
+		return nil, "无法解压：" + err.Error()
+	}
+	if len(data) > maxImportFileSize {
+		return nil, fmt.Sprintf("文件超过%dKB", maxImportFileSize>>10)
+	}
+	if !utf8.Valid(data) {
+		return nil, "文件不是UTF-8编码"
+	}
+
+	matter, body := markdown.SplitFrontMatter(string(data))
+	var meta frontMatter
+	if err := yaml.Unmarshal([]byte(matter), &meta); err != nil {
+		return nil, "front matter格式不正确：" + err.Error()
+	}
+
+	name := strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name))
+	var fileDate string
+	if m := datedFilename.FindStringSubmatch(name); m != nil {
+		fileDate, name = m[1], m[2]
+	}
+
+	post := &markdownPost{
+		article: &Article{
+			Title:         strings.TrimSpace(firstNonEmpty(meta.Title, name)),
+			Desc:          strings.TrimSpace(firstNonEmpty(meta.Description, meta.Excerpt, summary(body))),
+			Content:       body,
+			CoverImageUrl: strings.TrimSpace(firstNonEmpty(meta.Cover, meta.Image)),
+			State:         1,
+		},
+		tags: scalarList(&meta.Tags),
+	}
+	article := post.article
+	if meta.Draft || (meta.Published != nil && !*meta.Published) {
+		article.State = 0
+	}
+
+	date, err := parseImportDate(firstNonEmpty(meta.Date.Value, fileDate))
+	if err != nil {
+		return post, "无法解析日期：" + meta.Date.Value
+	}
+	if !date.IsZero() {
+		article.CreatedOn = int(date.Unix())
+		//日期在未来的文章到时间后再发布
+		if article.State == 1 && date.After(time.Now()) {
+			article.PublishAt = article.CreatedOn
+		}
+	}
+
+	return post, validPost(post)
+}
+
+// 与新建文章的表单使用相同的长度限制，封面和标签可以为空
+func validPost(post *markdownPost) string {
+	article := post.article
+	switch {
+	case article.Title == "":
+		return "标题不能为空"
+	case utf8.RuneCountInString(article.Title) > 100:
+		return "标题最长为100字符"
+	case utf8.RuneCountInString(article.Desc) > 255:
+		return "简述最长为255字符"
+	case strings.TrimSpace(article.Content) == "":
+		return "内容不能为空"
+	case utf8.RuneCountInString(article.Content) > 65535:
+		return "内容最长为65535字符"
+	case utf8.RuneCountInString(article.CoverImageUrl) > 255:
+		return "封面图片地址最长为255字符"
+	}
+
+	for _, tag := range post.tags {
+		if utf8.RuneCountInString(tag) > 100 {
+			return "标签名称最长为100字符"
+		}
+	}
+
+	return ""
+}
+
+// 日期为空时返回零值
+func parseImportDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown date format: %s", value)
+}
+
+// scalarList 同时支持列表和单个值，单个值按逗号分隔，结果去掉空值和重复值
+func scalarList(node *yaml.Node) []string {
+	var values []string
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				values = append(values, item.Value)
+			}
+		}
+	case yaml.ScalarNode:
+		values = strings.Split(node.Value, ",")
+	}
+
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !seen[strings.ToLower(value)] {
+			seen[strings.ToLower(value)] = true
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// summary 取正文的第一段作为简述，Hexo 中 <!-- more --> 之前的部分优先
+func summary(body string) string {
+	if i := strings.Index(body, "<!-- more -->"); i >= 0 {
+		body = body[:i]
+	}
+
+	var paragraph []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "```") ||
+			strings.HasPrefix(line, "![") || strings.HasPrefix(line, "<") {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+
+	text := []rune(strings.Join(paragraph, " "))
+	if len(text) > importDescSize {
+		return string(text[:importDescSize]) + "…"
+	}
+
+	return string(text)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+
+	return ""
+}
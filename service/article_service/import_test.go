@@ -0,0 +1,324 @@
+package article_service
+
+import (
+	"archive/zip"
+	"bytes"
+	"gin-blog/models"
+	"gin-blog/service/tag_service"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type importFile struct {
+	name    string
+	content string
+}
+
+// buildZip 在内存中创建 zip 压缩包，name 以 / 结尾时为目录
+func buildZip(t *testing.T, files []importFile) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, file := range files {
+		f, err := w.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(file.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func localUnix(t *testing.T, layout, value string) int {
+	t.Helper()
+
+	date, err := time.ParseInLocation(layout, value, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return int(date.Unix())
+}
+
+func reportFiles(files []MarkdownImportFile) []string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.File)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func tagNames(article *models.Article) []string {
+	names := make([]string, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		names = append(names, tag.Name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// importedArticle 按报告中的文件名读取导入的文章
+func importedArticle(t *testing.T, report *MarkdownImportReport, file string) *models.Article {
+	t.Helper()
+
+	for _, created := range report.Created {
+		if created.File == file {
+			article, err := (&Article{ID: created.ID}).Get()
+			if err != nil || article == nil {
+				t.Fatal(article, err)
+			}
+			return article
+		}
+	}
+	t.Fatalf("%s was not created: %+v", file, report)
+
+	return nil
+}
+
+func TestImportMarkdown(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       []importFile
+		addTags     bool
+		wantCreated []string
+		wantSkipped []string
+		wantFailed  []string
+		//失败原因中应包含的内容，按文件名
+		wantReasons map[string]string
+		check       func(t *testing.T, report *MarkdownImportReport)
+	}{
+		{
+			name: "hexo",
+			files: []importFile{{"hexo.md", "---\ntitle: Hello Hexo\ndate: 2019-01-02 03:04:05\ntags:\n  - go\n  - Web\n" +
+				"description: 简述\ncover: /upload/images/a.jpg\n---\n正文\n"}},
+			addTags:     true,
+			wantCreated: []string{"hexo.md"},
+			check: func(t *testing.T, report *MarkdownImportReport) {
+				article := importedArticle(t, report, "hexo.md")
+				if article.Title != "Hello Hexo" || article.Desc != "简述" || article.Content != "正文\n" ||
+					article.CoverImageUrl != "/upload/images/a.jpg" || article.State != 1 || article.PublishAt != 0 {
+					t.Errorf("article = %+v", article)
+				}
+				if want := localUnix(t, "2006-01-02 15:04:05", "2019-01-02 03:04:05"); article.CreatedOn != want {
+					t.Errorf("CreatedOn = %d, want %d", article.CreatedOn, want)
+				}
+				//go 已经存在，Web 自动创建
+				if got := tagNames(article); strings.Join(got, ",") != "Web,go" {
+					t.Errorf("tags = %v", got)
+				}
+			},
+		},
+		{
+			name: "jekyll dated filename",
+			files: []importFile{
+				{"_posts/", ""},
+				{"_posts/2018-05-06-hello-world.markdown", "---\nlayout: post\n---\n# 标题\n\n第一段\n第一段续\n\n第二段\n"},
+			},
+			wantCreated: []string{"_posts/2018-05-06-hello-world.markdown"},
+			check: func(t *testing.T, report *MarkdownImportReport) {
+				article := importedArticle(t, report, "_posts/2018-05-06-hello-world.markdown")
+				if article.Title != "hello-world" || article.Desc != "第一段 第一段续" {
+					t.Errorf("article = %+v", article)
+				}
+				if want := localUnix(t, "2006-01-02", "2018-05-06"); article.CreatedOn != want {
+					t.Errorf("CreatedOn = %d, want %d", article.CreatedOn, want)
+				}
+			},
+		},
+		{
+			name:        "scalar tags",
+			files:       []importFile{{"scalar.md", "---\ntitle: Scalar\ntags: go, Go , ,web\n---\nbody\n"}},
+			addTags:     true,
+			wantCreated: []string{"scalar.md"},
+			check: func(t *testing.T, report *MarkdownImportReport) {
+				if got := tagNames(importedArticle(t, report, "scalar.md")); strings.Join(got, ",") != "go,web" {
+					t.Errorf("tags = %v", got)
+				}
+			},
+		},
+		{
+			name: "drafts",
+			files: []importFile{
+				{"draft.md", "---\ntitle: Draft\ndraft: true\n---\nbody\n"},
+				{"unpublished.md", "---\ntitle: Unpublished\npublished: false\n---\nbody\n"},
+				{"published.md", "---\ntitle: Published\npublished: true\n---\nbody\n"},
+			},
+			wantCreated: []string{"draft.md", "published.md", "unpublished.md"},
+			check: func(t *testing.T, report *MarkdownImportReport) {
+				for file, want := range map[string]int{"draft.md": 0, "unpublished.md": 0, "published.md": 1} {
+					if got := importedArticle(t, report, file).State; got != want {
+						t.Errorf("%s state = %d, want %d", file, got, want)
+					}
+				}
+			},
+		},
+		{
+			name: "future date",
+			files: []importFile{
+				{"future.md", "---\ntitle: Future\ndate: 2999-01-02\n---\nbody\n"},
+				{"future-draft.md", "---\ntitle: Future Draft\ndate: 2999-01-02\ndraft: true\n---\nbody\n"},
+			},
+			wantCreated: []string{"future-draft.md", "future.md"},
+			check: func(t *testing.T, report *MarkdownImportReport) {
+				//定时发布的文章在发布前保持草稿状态
+				want := localUnix(t, "2006-01-02", "2999-01-02")
+				if article := importedArticle(t, report, "future.md"); article.PublishAt != want || article.State != 0 {
+					t.Errorf("future PublishAt = %d, state = %d, want %d", article.PublishAt, article.State, want)
+				}
+				//草稿不会定时发布
+				if article := importedArticle(t, report, "future-draft.md"); article.PublishAt != 0 {
+					t.Errorf("future draft PublishAt = %d, want 0", article.PublishAt)
+				}
+			},
+		},
+		{
+			name: "duplicate titles",
+			files: []importFile{
+				{"existing.md", "---\ntitle: Existing\n---\nbody\n"},
+				{"a/same.md", "---\ntitle: Same\n---\nbody\n"},
+				{"b/same.md", "---\ntitle: Same\n---\nother body\n"},
+			},
+			wantCreated: []string{"a/same.md"},
+			wantSkipped: []string{"b/same.md", "existing.md"},
+		},
+		{
+			name: "invalid files",
+			files: []importFile{
+				{"large.md", "---\ntitle: Large\n---\n" + strings.Repeat("a", maxImportFileSize)},
+				{"latin1.md", "caf\xe9"},
+				{"yaml.md", "---\ntitle: [unclosed\n---\nbody\n"},
+				{"date.md", "---\ntitle: Date\ndate: yesterday\n---\nbody\n"},
+				{"empty.md", "---\ntitle: Empty\n---\n\n"},
+				{"long.md", "---\ntitle: " + strings.Repeat("长", 101) + "\n---\nbody\n"},
+			},
+			wantFailed: []string{"date.md", "empty.md", "large.md", "latin1.md", "long.md", "yaml.md"},
+			wantReasons: map[string]string{
+				"large.md":  "文件超过1024KB",
+				"latin1.md": "UTF-8",
+				"yaml.md":   "front matter",
+				"date.md":   "无法解析日期",
+				"empty.md":  "内容不能为空",
+				"long.md":   "标题最长为100字符",
+			},
+		},
+		{
+			name: "ignored files",
+			files: []importFile{
+				{"README.txt", "not markdown"},
+				{".hidden.md", "---\ntitle: Hidden\n---\nbody\n"},
+				{"__MACOSX/._post.md", "metadata"},
+				{"post.MD", "---\ntitle: Post\n---\nbody\n"},
+			},
+			wantCreated: []string{"post.MD"},
+		},
+		{
+			name: "unknown tags without permission",
+			files: []importFile{
+				{"known.md", "---\ntitle: Known\ntags: [go]\n---\nbody\n"},
+				{"unknown.md", "---\ntitle: Unknown\ntags: [go, rust]\n---\nbody\n"},
+			},
+			wantCreated: []string{"known.md"},
+			wantFailed:  []string{"unknown.md"},
+			wantReasons: map[string]string{"unknown.md": "标签不存在：rust"},
+			check: func(t *testing.T, report *MarkdownImportReport) {
+				missing, err := (&tag_service.Tag{}).MissingNames([]string{"rust"})
+				if err != nil || len(missing) != 1 {
+					t.Errorf("rust was created: %v, %v", missing, err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			addTag(t, "go")
+			addArticle(t, "Existing", nil)
+
+			r := buildZip(t, tt.files)
+			report, err := ImportMarkdown(r, r.Size(), "importer", tt.addTags)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, list := range []struct {
+				name  string
+				got   []MarkdownImportFile
+				wants []string
+			}{
+				{"created", report.Created, tt.wantCreated},
+				{"skipped", report.Skipped, tt.wantSkipped},
+				{"failed", report.Failed, tt.wantFailed},
+			} {
+				if got := reportFiles(list.got); strings.Join(got, ",") != strings.Join(list.wants, ",") {
+					t.Errorf("%s = %v, want %v", list.name, got, list.wants)
+				}
+			}
+			for _, failed := range report.Failed {
+				if want := tt.wantReasons[failed.File]; !strings.Contains(failed.Reason, want) {
+					t.Errorf("%s reason = %q, want %q", failed.File, failed.Reason, want)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, report)
+			}
+		})
+	}
+}
+
+func TestImportMarkdownInvalidZip(t *testing.T) {
+	setupTest(t)
+
+	r := strings.NewReader("not a zip")
+	if _, err := ImportMarkdown(r, r.Size(), "importer", true); err == nil {
+		t.Error("ImportMarkdown() = nil error, want ErrInvalidImportZip")
+	}
+}
+
+func TestParseImportDate(t *testing.T) {
+	tests := []struct {
+		value  string
+		layout string
+	}{
+		{"2019-01-02 03:04:05 +0800", "2006-01-02 15:04:05 -0700"},
+		{"2019-01-02 03:04:05", "2006-01-02 15:04:05"},
+		{"2019-01-02 03:04", "2006-01-02 15:04"},
+		{"2019-01-02T03:04:05Z", "2006-01-02T15:04:05Z07:00"},
+		{"2019-01-02T03:04:05+08:00", "2006-01-02T15:04:05Z07:00"},
+		{"2019-01-02T03:04:05", "2006-01-02T15:04:05"},
+		{"2019-01-02", "2006-01-02"},
+		{"2019/01/02 03:04:05", "2006/01/02 15:04:05"},
+		{" 2019/01/02 ", "2006/01/02"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseImportDate(tt.value)
+		if err != nil {
+			t.Errorf("parseImportDate(%q) = %v", tt.value, err)
+			continue
+		}
+		want, _ := time.ParseInLocation(tt.layout, strings.TrimSpace(tt.value), time.Local)
+		if !got.Equal(want) {
+			t.Errorf("parseImportDate(%q) = %v, want %v", tt.value, got, want)
+		}
+	}
+
+	if got, err := parseImportDate(""); err != nil || !got.IsZero() {
+		t.Errorf("parseImportDate(\"\") = %v, %v, want zero", got, err)
+	}
+	if _, err := parseImportDate("02/01/2019"); err == nil {
+		t.Error("parseImportDate(\"02/01/2019\") = nil error")
+	}
+}
@@ -0,0 +1,154 @@
+package article_service
+
+import (
+	"bytes"
+	"fmt"
+	"gin-blog/models"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/poster"
+	"gin-blog/pkg/qrcode"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"gin-blog/pkg/upload"
+	"gin-blog/pkg/util"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// 海报底部二维码的原始边长，绘制时按海报宽度缩放
+const qrCodeSize = 300
+
+// Poster 文章的分享海报
+type Poster struct {
+	Article *models.Article
+}
+
+// Generate 生成海报并返回文件名。文件名由文章内容和海报配置计算得到，文章没有修改时直接使用已经生成的海报
+func (p *Poster) Generate() (string, error) {
+	url := util.ArticleURL(p.Article.ID)
+	name := "poster-" + strconv.Itoa(p.Article.ID) + "-" + util.Md5(fmt.Sprint(
+		p.Article.ModifiedOn, p.Article.Title, p.Article.Desc, p.Article.CoverImageUrl, url,
+		setting.AppSetting.PosterTemplate, setting.AppSetting.PosterFont,
+	)) + ".png"
+	if _, err := storage.Default.Stat(poster.GetPosterPath() + name); err == nil {
+		return name, nil
+	}
+
+	_, err, _ := group.Do("POSTER_"+name, func() (interface{}, error) {
+		qr, err := qrcode.Encode(url, qrCodeSize)
+		if err != nil {
+			return nil, err
+		}
+
+		img, err := (&poster.Poster{
+			Title:   p.Article.Title,
+			Desc:    p.Article.Desc,
+			Cover:   p.cover(),
+			QrCode:  qr,
+			Caption: "扫码阅读全文",
+		}).Draw()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := poster.Save(img, name); err != nil {
+			return nil, err
+		}
+
+		p.removeOutdated(name)
+		return nil, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// cover 只读取上传到本站的封面图，不请求外部地址，读取失败时海报不包含封面
+func (p *Poster) cover() image.Image {
+	src, ok := coverKey(p.Article.CoverImageUrl)
+	if !ok || !strings.HasPrefix(src, upload.GetImagePath()) {
+		return nil
+	}
+
+	//以 / 开头的路径 Clean 后不会包含 ../，防止读取图片目录以外的文件
+	name := path.Clean("/" + strings.TrimPrefix(src, upload.GetImagePath()))
+	r, err := storage.Default.Get(upload.GetImagePath() + strings.TrimPrefix(name, "/"))
+	if err != nil {
+		logging.Warn(err)
+		return nil
+	}
+	defer r.Close()
+
+	//先按上传时的限制检查文件大小和图片尺寸，避免解码过大的图片
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(setting.AppSetting.ImageMaxSize)+1))
+	if err != nil {
+		logging.Warn(err)
+		return nil
+	}
+	if !upload.CheckImageSize(int64(len(data))) {
+		logging.Warn("poster cover is too large:", name)
+		return nil
+	}
+	f := bytes.NewReader(data)
+	if err := upload.CheckImageContent(f, name); err != nil {
+		logging.Warn(err)
+		return nil
+	}
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		logging.Warn(err)
+		return nil
+	}
+
+	return img
+}
+
+// coverKey 将封面地址转换为存储中的 key。封面地址由 storage.Default.URL 生成，使用 S3 时以存储桶或 CDN 地址开头；
+// 也兼容以 PrefixUrl 开头的地址和站内的相对路径
+func coverKey(src string) (string, bool) {
+	for _, prefix := range []string{
+		storage.Default.URL(""),
+		strings.TrimSuffix(setting.AppSetting.PrefixUrl, "/") + "/",
+		"/",
+	} {
+		if strings.HasPrefix(src, prefix) {
+			src = strings.TrimPrefix(src, prefix)
+			break
+		}
+	}
+
+	//S3 的地址中 key 经过了编码
+	key, err := url.PathUnescape(src)
+	if err != nil {
+		return "", false
+	}
+
+	return key, true
+}
+
+// removeOutdated 删除文章修改之前生成的海报，删除失败只记录日志
+func (p *Poster) removeOutdated(current string) {
+	objects, err := storage.Default.List(poster.GetPosterPath() + "poster-" + strconv.Itoa(p.Article.ID) + "-")
+	if err != nil {
+		logging.Warn(err)
+		return
+	}
+
+	for _, object := range objects {
+		if path.Base(object.Key) != current {
+			if err := storage.Default.Delete(object.Key); err != nil {
+				logging.Warn(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package article_service
+
+import (
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"testing"
+)
+
+func TestCoverKey(t *testing.T) {
+	s3, err := storage.NewS3(&storage.S3Config{
+		Endpoint:  "http://127.0.0.1:9000",
+		Bucket:    "blog",
+		PathStyle: true,
+		PublicUrl: "https://cdn.example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		storage storage.Storage
+		src     string
+		want    string
+	}{
+		{"local", storage.NewLocal("runtime/", "http://localhost:8080"), "http://localhost:8080/upload/images/ab/cd/a.jpg", "upload/images/ab/cd/a.jpg"},
+		{"local relative", storage.NewLocal("runtime/", "http://localhost:8080"), "/upload/images/ab/cd/a.jpg", "upload/images/ab/cd/a.jpg"},
+		{"s3", s3, "https://cdn.example.com/upload/images/ab/cd/a.jpg", "upload/images/ab/cd/a.jpg"},
+		{"s3 escaped", s3, "https://cdn.example.com/upload/images/a%20b.jpg", "upload/images/a b.jpg"},
+		{"s3 prefix url", s3, "http://localhost:8080/upload/images/ab/cd/a.jpg", "upload/images/ab/cd/a.jpg"},
+		{"external", s3, "https://example.com/upload/images/a.jpg", "https://example.com/upload/images/a.jpg"},
+	}
+
+	defer func(s storage.Storage) { storage.Default = s }(storage.Default)
+	setting.AppSetting.PrefixUrl = "http://localhost:8080"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage.Default = tt.storage
+			if got, ok := coverKey(tt.src); !ok || got != tt.want {
+				t.Errorf("coverKey(%q) = %q, %v, want %q", tt.src, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package article_service
+
+import (
+	"fmt"
+	"gin-blog/models"
+	"gin-blog/pkg/diff"
+	"strconv"
+)
+
+// diff 中每段差异前后保留的相同行数
+const diffContext = 3
+
+type Revision struct {
+	ID         int
+	ArticleID  int
+	ModifiedBy string
+
+	PageNum  int
+	PageSize int
+}
+
+func (r *Revision) GetAll() ([]models.ArticleRevision, error) {
+	return models.GetArticleRevisions(r.ArticleID, r.PageNum, r.PageSize)
+}
+
+func (r *Revision) Count() (int, error) {
+	return models.GetArticleRevisionTotal(r.ArticleID)
+}
+
+// Get 获取文章的指定版本，不存在时返回 nil
+func (r *Revision) Get() (*models.ArticleRevision, error) {
+	revision, err := models.GetArticleRevision(r.ArticleID, r.ID)
+	if err != nil || revision.ID == 0 {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// Diff 比较 from、to 两个版本，to 为 nil 时与文章的当前内容比较
+func (r *Revision) Diff(from *models.ArticleRevision, to *models.ArticleRevision) (string, error) {
+	toName := "current"
+	if to == nil {
+		article, err := models.GetArticle(r.ArticleID)
+		if err != nil {
+			return "", err
+		}
+		to = &models.ArticleRevision{
+			Title:         article.Title,
+			Desc:          article.Desc,
+			Content:       article.Content,
+			CoverImageUrl: article.CoverImageUrl,
+		}
+	} else {
+		toName = "revision-" + strconv.Itoa(to.ID)
+	}
+
+	return diff.Unified("revision-"+strconv.Itoa(from.ID), toName, revisionText(from), revisionText(to), diffContext), nil
+}
+
+// Restore 将文章恢复为该版本的内容，恢复前的内容同样会保存为一个历史版本，标签和发布状态不变
+func (r *Revision) Restore(revision *models.ArticleRevision) error {
+	err := models.EditArticle(r.ArticleID, map[string]interface{}{
+		"title":           revision.Title,
+		"desc":            revision.Desc,
+		"content":         revision.Content,
+		"cover_image_url": revision.CoverImageUrl,
+		"modified_by":     r.ModifiedBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	article := Article{ID: r.ArticleID}
+	article.afterWrite()
+	return nil
+}
+
+// 标题、简述、封面和正文拼成一份文本进行比较
+func revisionText(r *models.ArticleRevision) string {
+	return fmt.Sprintf("Title: %s\nDesc: %s\nCover: %s\n\n%s", r.Title, r.Desc, r.CoverImageUrl, r.Content)
+}
@@ -0,0 +1,37 @@
+package article_service
+
+import (
+	"gin-blog/models"
+	"testing"
+	"time"
+)
+
+// 清空回收站只删除被删除文章的历史版本
+func TestCleanAllArticleKeepsRevisions(t *testing.T) {
+	setupTest(t)
+	deleted := addArticle(t, "deleted", nil)
+	kept := []*Article{addArticle(t, "kept 1", nil), addArticle(t, "kept 2", nil)}
+	for _, article := range append(kept, deleted) {
+		article.Content = "edited"
+		if err := article.Edit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := deleted.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := models.CleanAllArticle(int(time.Now().Unix()) + 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, article := range append(kept, deleted) {
+		want := 1
+		if article == deleted {
+			want = 0
+		}
+		if count, err := (&Revision{ArticleID: article.ID}).Count(); err != nil || count != want {
+			t.Errorf("%s revisions = %d, %v, want %d", article.Title, count, err, want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package article_service
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/search"
+	"gin-blog/pkg/setting"
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// 搜索结果中正文摘要的长度
+const snippetSize = 120
+
+// 重建索引时每次从数据库读取的文章数量
+const rebuildBatchSize = 100
+
+var index search.Index = search.NewNone()
+
+// SetIndex 设置文章使用的搜索索引，未设置时搜索结果为空
+func SetIndex(i search.Index) {
+	index = i
+}
+
+// NewIndex 根据配置 [search] Type 创建搜索索引，local 索引会立即从数据库重建
+func NewIndex() (search.Index, error) {
+	if setting.SearchSetting.Type == "mysql" {
+		return mysqlIndex{}, nil
+	}
+
+	i, err := search.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := rebuildIndex(i); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// Search 全文搜索文章，筛选条件与 GetAll 相同
+type Search struct {
+	Query         string
+	TagIDs        []int
+	MatchAllTags  bool
+	State         int
+	PublishedOnly bool
+
+	PageNum  int
+	PageSize int
+}
+
+// SearchResult 搜索命中的文章，Highlights 中命中的词用 <em> 标出
+type SearchResult struct {
+	*models.Article
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// Do 返回当前页的搜索结果和结果总数
+func (s *Search) Do() ([]*SearchResult, int, error) {
+	q := &search.Query{
+		Text:         s.Query,
+		TagIDs:       s.TagIDs,
+		MatchAllTags: s.MatchAllTags,
+		State:        s.State,
+		PageNum:      s.PageNum,
+		PageSize:     s.PageSize,
+	}
+	if s.PublishedOnly {
+		q.PublishedAt = int(time.Now().Unix())
+	}
+
+	hits, total, err := index.Search(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]int, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	articles, err := models.GetArticlesByIDs(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[int]*models.Article, len(articles))
+	for _, article := range articles {
+		byID[article.ID] = article
+	}
+
+	terms := search.Tokenize(s.Query)
+	results := make([]*SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		//索引与数据库之间可能有短暂的不一致，已经不存在的文章直接跳过
+		article, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+
+		results = append(results, &SearchResult{
+			Article: article,
+			Score:   hit.Score,
+			Highlights: map[string]string{
+				"title":   search.Highlight(article.Title, terms, 0),
+				"desc":    search.Highlight(article.Desc, terms, 0),
+				"content": search.Highlight(article.Content, terms, snippetSize),
+			},
+		})
+	}
+
+	return results, total, nil
+}
+
+// 文章写入后同步到索引，文章已删除时从索引中移除，同步失败只记录日志
+func reindex(ids ...int) {
+	articles, err := models.GetArticlesByIDs(ids)
+	if err != nil {
+		logging.Warn(err)
+		return
+	}
+
+	found := make(map[int]bool, len(articles))
+	for _, article := range articles {
+		found[article.ID] = true
+		if err := index.Index(newDocument(article)); err != nil {
+			logging.Warn(err)
+		}
+	}
+	for _, id := range ids {
+		if !found[id] {
+			if err := index.Delete(id); err != nil {
+				logging.Warn(err)
+			}
+		}
+	}
+}
+
+func rebuildIndex(i search.Index) error {
+	for offset := 0; ; offset += rebuildBatchSize {
+		articles, err := models.GetArticles(offset, rebuildBatchSize, map[string]interface{}{"deleted_on": 0}, orderByID)
+		if err != nil {
+			return err
+		}
+		for _, article := range articles {
+			if err := i.Index(newDocument(article)); err != nil {
+				return err
+			}
+		}
+		if len(articles) < rebuildBatchSize {
+			return nil
+		}
+	}
+}
+
+// orderByID 分批读取时需要固定顺序
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+func newDocument(article *models.Article) *search.Document {
+	tagIDs := make([]int, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+
+	return &search.Document{
+		ID:          article.ID,
+		Title:       article.Title,
+		Desc:        article.Desc,
+		Content:     article.Content,
+		TagIDs:      tagIDs,
+		State:       article.State,
+		PublishAt:   article.PublishAt,
+		UnpublishAt: article.UnpublishAt,
+	}
+}
+
+// mysqlIndex 直接查询文章表上的 FULLTEXT 索引，索引由 MySQL 维护，不需要同步
+type mysqlIndex struct{}
+
+func (mysqlIndex) Index(doc *search.Document) error {
+	return nil
+}
+
+func (mysqlIndex) Delete(id int) error {
+	return nil
+}
+
+func (mysqlIndex) Search(q *search.Query) ([]search.Hit, int, error) {
+	a := Article{
+		TagIDs:        q.TagIDs,
+		MatchAllTags:  q.MatchAllTags,
+		State:         q.State,
+		PublishedOnly: q.PublishedAt > 0,
+	}
+
+	total, err := models.SearchArticleTotal(q.Text, a.getMaps(), a.scopes()...)
+	if err != nil || total == 0 {
+		return nil, total, err
+	}
+
+	scores, err := models.SearchArticles(q.Text, q.PageNum, q.PageSize, a.getMaps(), a.scopes()...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]search.Hit, 0, len(scores))
+	for _, score := range scores {
+		hits = append(hits, search.Hit{ID: score.ID, Score: score.Score})
+	}
+
+	return hits, total, nil
+}
@@ -0,0 +1,116 @@
+package article_service
+
+import (
+	"gin-blog/pkg/search"
+	"testing"
+)
+
+func setupSearchTest(t *testing.T) {
+	t.Helper()
+
+	setupTest(t)
+	SetIndex(search.NewLocal())
+	t.Cleanup(func() { SetIndex(search.NewNone()) })
+}
+
+func searchTitles(t *testing.T, query string) []string {
+	t.Helper()
+
+	results, total, err := (&Search{Query: query, State: -1, PageSize: 10}).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := make([]string, 0, len(results))
+	for _, result := range results {
+		titles = append(titles, result.Title)
+	}
+	if total != len(titles) {
+		t.Errorf("Search(%q) total = %d, want %d", query, total, len(titles))
+	}
+
+	return titles
+}
+
+// 文章写入后同步更新索引
+func TestSearchReindex(t *testing.T) {
+	setupSearchTest(t)
+
+	article := addArticle(t, "Gin入门", nil)
+	addArticle(t, "Beego入门", nil)
+	if got := searchTitles(t, "入门"); !equalStrings(got, []string{"Beego入门", "Gin入门"}) {
+		t.Errorf("Search(入门) = %v", got)
+	}
+
+	article.Title, article.Desc, article.Content = "Echo入门", "Echo入门", "Echo入门"
+	if err := article.Edit(); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchTitles(t, "gin"); len(got) != 0 {
+		t.Errorf("after edit Search(gin) = %v, want none", got)
+	}
+	if got := searchTitles(t, "echo"); !equalStrings(got, []string{"Echo入门"}) {
+		t.Errorf("after edit Search(echo) = %v", got)
+	}
+
+	if err := article.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchTitles(t, "入门"); !equalStrings(got, []string{"Beego入门"}) {
+		t.Errorf("after delete Search(入门) = %v", got)
+	}
+}
+
+// 重建索引包含已有的文章，不包含已删除的文章
+func TestSearchRebuild(t *testing.T) {
+	setupTest(t)
+
+	addArticle(t, "kept", nil)
+	if err := addArticle(t, "deleted", nil).Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	i := search.NewLocal()
+	if err := rebuildIndex(i); err != nil {
+		t.Fatal(err)
+	}
+	SetIndex(i)
+	t.Cleanup(func() { SetIndex(search.NewNone()) })
+
+	if got := searchTitles(t, "kept"); !equalStrings(got, []string{"kept"}) {
+		t.Errorf("Search(kept) = %v", got)
+	}
+	if got := searchTitles(t, "deleted"); len(got) != 0 {
+		t.Errorf("Search(deleted) = %v, want none", got)
+	}
+}
+
+// 高亮中的原文做 HTML 转义，只保留 <em>
+func TestSearchHighlights(t *testing.T) {
+	setupSearchTest(t)
+
+	article := &Article{
+		Title:     "<b>Go</b> tips",
+		Desc:      "desc",
+		Content:   `<script>alert("go")</script>`,
+		CreatedBy: "test",
+		State:     1,
+	}
+	if err := article.Add(); err != nil {
+		t.Fatal(err)
+	}
+
+	results, _, err := (&Search{Query: "GO", State: -1, PageSize: 10}).Do()
+	if err != nil || len(results) != 1 {
+		t.Fatal(results, err)
+	}
+	want := map[string]string{
+		"title":   "&lt;b&gt;<em>Go</em>&lt;/b&gt; tips",
+		"desc":    "desc",
+		"content": "&lt;script&gt;alert(&#34;<em>go</em>&#34;)&lt;/script&gt;",
+	}
+	for field, highlight := range want {
+		if got := results[0].Highlights[field]; got != highlight {
+			t.Errorf("Highlights[%s] = %s, want %s", field, got, highlight)
+		}
+	}
+}
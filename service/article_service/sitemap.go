@@ -0,0 +1,127 @@
+package article_service
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/gcache"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/sitemap"
+	"gin-blog/pkg/util"
+	"gin-blog/service/cache_service"
+	"gin-blog/service/tag_service"
+	"strconv"
+	"time"
+)
+
+// 生成站点地图时每次从数据库读取的文章数量
+const sitemapBatchSize = 1000
+
+// Sitemap 站点地图，依次包含首页、启用的标签和已发布的文章。
+// 地址不超过 sitemap.MaxURLs 时 Page 为0返回完整的站点地图，否则返回索引，Page 从1开始对应各个分页，没有拆分时不存在分页
+type Sitemap struct {
+	Page int
+}
+
+// Get 返回生成的站点地图，分页超出范围时返回 nil
+func (s *Sitemap) Get() ([]byte, error) {
+	cache := cache_service.Sitemap{Page: s.Page}
+	key := cache.GetSitemapKey(store)
+	if data, err := store.Get(key); err == nil {
+		return data, nil
+	} else if err != gcache.ErrNotFound {
+		logging.Info(err)
+	}
+
+	v, err, _ := group.Do(key, func() (interface{}, error) {
+		data, err := s.build()
+		if err != nil || data == nil {
+			return data, err
+		}
+
+		if err := store.Set(key, data, gcache.Jitter(cacheTTL)); err != nil {
+			logging.Info(err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return v.([]byte), nil
+}
+
+func (s *Sitemap) build() ([]byte, error) {
+	tagService := tag_service.Tag{State: 1}
+	tags, err := tagService.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	articles := Article{State: -1, PublishedOnly: true}
+	count, err := articles.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	total := 1 + len(tags) + count
+	pages := (total + sitemap.MaxURLs - 1) / sitemap.MaxURLs
+	if s.Page == 0 && pages > 1 {
+		sitemaps := make([]sitemap.URL, 0, pages)
+		for page := 1; page <= pages; page++ {
+			sitemaps = append(sitemaps, sitemap.URL{Loc: util.AbsoluteURL("/sitemaps/" + strconv.Itoa(page) + ".xml")})
+		}
+		return sitemap.Index(sitemaps)
+	}
+	if s.Page > pages || s.Page > 0 && pages == 1 {
+		return nil, nil
+	}
+
+	offset := 0
+	if s.Page > 0 {
+		offset = (s.Page - 1) * sitemap.MaxURLs
+	}
+
+	//首页和标签排在文章之前
+	urls := []sitemap.URL{{Loc: util.AbsoluteURL("/")}}
+	for _, tag := range tags {
+		urls = append(urls, sitemap.URL{Loc: util.TagURL(tag.ID), LastMod: lastMod(tag.ModifiedOn)})
+	}
+	if offset < len(urls) {
+		urls = urls[offset:]
+		offset = 0
+	} else {
+		offset -= len(urls)
+		urls = nil
+	}
+	if len(urls) > sitemap.MaxURLs {
+		urls = urls[:sitemap.MaxURLs]
+	}
+
+	for len(urls) < sitemap.MaxURLs {
+		size := sitemap.MaxURLs - len(urls)
+		if size > sitemapBatchSize {
+			size = sitemapBatchSize
+		}
+
+		list, err := models.GetArticleLastMods(offset, size, articles.getMaps(), articles.scopes()...)
+		if err != nil {
+			return nil, err
+		}
+		for _, article := range list {
+			urls = append(urls, sitemap.URL{Loc: util.ArticleURL(article.ID), LastMod: lastMod(article.ModifiedOn)})
+		}
+		if len(list) < size {
+			break
+		}
+		offset += size
+	}
+
+	return sitemap.URLSet(urls)
+}
+
+func lastMod(modifiedOn int) time.Time {
+	if modifiedOn == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(modifiedOn), 0)
+}
@@ -0,0 +1,131 @@
+package article_service
+
+import (
+	"encoding/xml"
+	"gin-blog/models"
+	"gin-blog/pkg/sitemap"
+	"github.com/jinzhu/gorm"
+	"strconv"
+	"testing"
+)
+
+func TestSitemapGolden(t *testing.T) {
+	conn := setupSiteTest(t)
+	addFeedArticles(t, conn)
+	err := conn.Model(&models.Tag{}).UpdateColumn("modified_on", 1677877567).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := addArticle(t, "deleted", nil)
+	if err := deleted.Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := (&Sitemap{}).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "sitemap.xml", data)
+
+	//地址不超过 MaxURLs 时没有分页
+	if data, err := (&Sitemap{Page: 1}).Get(); err != nil || data != nil {
+		t.Errorf("page 1 = %s, %v, want nil", data, err)
+	}
+}
+
+// insertArticles 直接插入 n 篇已发布的文章，避免逐篇添加耗时过长
+func insertArticles(t *testing.T, conn *gorm.DB, n int) {
+	t.Helper()
+
+	err := conn.Exec(`INSERT INTO blog_article (title, state, publish_at, unpublish_at, created_on, modified_on, deleted_on)
+		WITH RECURSIVE seq(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM seq WHERE x < ?)
+		SELECT 'title', 1, 0, 0, 1700000000, 1700000000, 0 FROM seq`, n).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type sitemapDoc struct {
+	XMLName  xml.Name
+	URLs     []string `xml:"url>loc"`
+	Sitemaps []string `xml:"sitemap>loc"`
+}
+
+func getSitemap(t *testing.T, page int) *sitemapDoc {
+	t.Helper()
+
+	data, err := (&Sitemap{Page: page}).Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data == nil {
+		return nil
+	}
+
+	var doc sitemapDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	return &doc
+}
+
+func TestSitemapSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		//除首页和一个标签外的文章数
+		articles int
+		//每页的地址数，为空时不拆分
+		pages []int
+	}{
+		{"max urls", sitemap.MaxURLs - 2, nil},
+		{"one more", sitemap.MaxURLs - 1, []int{sitemap.MaxURLs, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := setupSiteTest(t)
+			tagID := addTag(t, "Go")
+			insertArticles(t, conn, tt.articles)
+
+			index := getSitemap(t, 0)
+			if tt.pages == nil {
+				if index.XMLName.Local != "urlset" || len(index.URLs) != sitemap.MaxURLs {
+					t.Fatalf("sitemap = %s with %d urls, want urlset with %d", index.XMLName.Local, len(index.URLs), sitemap.MaxURLs)
+				}
+				if data, err := (&Sitemap{Page: 1}).Get(); err != nil || data != nil {
+					t.Errorf("page 1 = %d bytes, %v, want nil", len(data), err)
+				}
+				return
+			}
+
+			if index.XMLName.Local != "sitemapindex" || len(index.Sitemaps) != len(tt.pages) {
+				t.Fatalf("sitemap = %s with %v, want sitemapindex with %d pages", index.XMLName.Local, index.Sitemaps, len(tt.pages))
+			}
+
+			//各页依次为首页、标签和按ID排列的文章，不重复、不遗漏
+			want := []string{"http://localhost:8080/", "http://localhost:8080/api/public/articles?tag_id=" + strconv.Itoa(tagID)}
+			for id := 1; id <= tt.articles; id++ {
+				want = append(want, "http://localhost:8080/api/public/articles/"+strconv.Itoa(id))
+			}
+			var got []string
+			for i, size := range tt.pages {
+				if loc := "http://localhost:8080/sitemaps/" + strconv.Itoa(i+1) + ".xml"; index.Sitemaps[i] != loc {
+					t.Errorf("sitemap %d = %s, want %s", i+1, index.Sitemaps[i], loc)
+				}
+				page := getSitemap(t, i+1)
+				if page == nil || page.XMLName.Local != "urlset" || len(page.URLs) != size {
+					t.Fatalf("page %d has %d urls, want %d", i+1, len(page.URLs), size)
+				}
+				got = append(got, page.URLs...)
+			}
+			if !equalStrings(got, want) {
+				t.Errorf("urls of all pages do not match, got %d, want %d", len(got), len(want))
+			}
+
+			if page := getSitemap(t, len(tt.pages)+1); page != nil {
+				t.Errorf("page %d = %d urls, want nil", len(tt.pages)+1, len(page.URLs))
+			}
+		})
+	}
+}
@@ -1,12 +1,180 @@
 package auth_service
 
-import "gin-blog/models"
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/util"
+)
 
 type Auth struct {
+	ID       int
 	Username string
 	Password string
+	Role     string
+
+	PageNum  int
+	PageSize int
 }
 
+// Check 校验用户名和明文密码，旧版 MD5 哈希校验通过后会透明地升级为 bcrypt
 func (a *Auth) Check() (bool, error) {
-	return models.CheckAuth(a.Username, a.Password)
+	auth, err := models.GetAuthByUsername(a.Username)
+	if err != nil {
+		return false, err
+	}
+	if auth.ID == 0 {
+		return false, nil
+	}
+
+	ok, needRehash := util.CheckPassword(auth.Password, a.Password)
+	if !ok {
+		return false, nil
+	}
+
+	a.ID = auth.ID
+	if needRehash {
+		if err := a.setPassword(); err != nil {
+			//升级失败不影响本次登录，下次登录会再次尝试
+			logging.Warn(err)
+		}
+	}
+
+	return true, nil
+}
+
+func (a *Auth) Get() (*models.Auth, error) {
+	return models.GetAuth(a.ID)
+}
+
+func (a *Auth) GetAll() ([]models.Auth, error) {
+	return models.GetAuths(a.PageNum, a.PageSize, a.getMaps())
+}
+
+func (a *Auth) Count() (int, error) {
+	return models.GetAuthTotal(a.getMaps())
+}
+
+func (a *Auth) ExistByID() (bool, error) {
+	return models.ExistAuthByID(a.ID)
+}
+
+func (a *Auth) ExistByUsername() (bool, error) {
+	return models.ExistAuthByUsername(a.Username)
+}
+
+// AddFirst 还没有任何用户时新建第一个用户，已经存在用户时返回 false
+func (a *Auth) AddFirst() (bool, error) {
+	hash, err := util.HashPassword(a.Password)
+	if err != nil {
+		return false, err
+	}
+
+	return models.AddFirstAuth(map[string]interface{}{
+		"username": a.Username,
+		"password": hash,
+		"role":     a.Role,
+	})
+}
+
+func (a *Auth) Add() error {
+	hash, err := util.HashPassword(a.Password)
+	if err != nil {
+		return err
+	}
+
+	return models.AddAuth(map[string]interface{}{
+		"username": a.Username,
+		"password": hash,
+		"role":     a.Role,
+	})
+}
+
+// Edit 修改用户名和角色，Password 不为空时同时重置密码。
+// 用户名、角色或密码变化时注销该用户所有的 token：token 中的角色和用户名在过期前一直有效，
+// 不注销时降级的用户仍有原来的权限，改名后旧 token 会被当作之后使用该用户名的用户
+func (a *Auth) Edit() error {
+	user, err := models.GetAuth(a.ID)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]interface{})
+	if a.Username != "" {
+		data["username"] = a.Username
+	}
+	if a.Role != "" {
+		data["role"] = a.Role
+	}
+	if a.Password != "" {
+		hash, err := util.HashPassword(a.Password)
+		if err != nil {
+			return err
+		}
+		data["password"] = hash
+	}
+
+	if err := models.EditAuth(a.ID, data); err != nil {
+		return err
+	}
+	changed := a.Password != "" ||
+		a.Username != "" && a.Username != user.Username ||
+		a.Role != "" && a.Role != user.Role
+	if !changed {
+		return nil
+	}
+
+	return RevokeUserTokens(user.Username)
+}
+
+// ChangePassword 校验原密码后修改为新密码，并注销该用户所有的 token，需要重新登录
+func (a *Auth) ChangePassword(newPassword string) (bool, error) {
+	ok, err := a.Check()
+	if err != nil || !ok {
+		return false, err
+	}
+
+	a.Password = newPassword
+	if err := a.setPassword(); err != nil {
+		return false, err
+	}
+	if err := RevokeUserTokens(a.Username); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Delete 删除用户并注销该用户所有的 token
+func (a *Auth) Delete() error {
+	user, err := models.GetAuth(a.ID)
+	if err != nil {
+		return err
+	}
+	if err := models.DeleteAuth(a.ID); err != nil {
+		return err
+	}
+
+	return RevokeUserTokens(user.Username)
+}
+
+func (a *Auth) setPassword() error {
+	hash, err := util.HashPassword(a.Password)
+	if err != nil {
+		return err
+	}
+
+	return models.EditAuth(a.ID, map[string]interface{}{"password": hash})
+}
+
+func (a *Auth) getMaps() map[string]interface{} {
+	maps := make(map[string]interface{})
+	maps["deleted_on"] = 0
+	if a.Username != "" {
+		maps["username"] = a.Username
+	}
+	if a.Role != "" {
+		maps["role"] = a.Role
+	}
+
+	return maps
 }
@@ -0,0 +1,32 @@
+package auth_service
+
+import (
+	"testing"
+)
+
+func TestEditRevokesTokens(t *testing.T) {
+	tests := []struct {
+		name        string
+		edit        Auth
+		wantRevoked bool
+	}{
+		{"nothing changed", Auth{Username: "author", Role: "author"}, false},
+		{"role changed", Auth{Role: "reader"}, true},
+		{"username changed", Auth{Username: "renamed"}, true},
+		{"password changed", Auth{Password: "new-password"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			user := addUser(t, "author", "author")
+			_, claims := issue(t, user)
+
+			tt.edit.ID = user.ID
+			if err := tt.edit.Edit(); err != nil {
+				t.Fatal(err)
+			}
+			assertRevoked(t, claims, tt.wantRevoked)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package auth_service
+
+import (
+	"encoding/json"
+	"errors"
+	"gin-blog/models"
+	"gin-blog/pkg/gredis"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/cache_service"
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+type Token struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// refreshSession refresh token 对应的用户和同时签发的 access token，AccessExpiresAt 为 access token 的过期时间
+type refreshSession struct {
+	Username        string `json:"username"`
+	AccessID        string `json:"access_id"`
+	AccessExpiresAt int64  `json:"access_expires_at"`
+}
+
+// IssueToken 为用户签发 access token 和 refresh token。
+// refresh token 与 access token 的 jti 绑定并记录到用户的会话中，注销时可以在服务端一并删除
+func IssueToken(user *models.Auth) (*Token, error) {
+	accessToken, claims, err := util.GenerateToken(user.Username, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := util.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := int(setting.AppSetting.RefreshTokenExpire / time.Second)
+	cache := cache_service.Token{ID: refreshToken}
+	session := refreshSession{Username: user.Username, AccessID: claims.Id, AccessExpiresAt: claims.ExpiresAt}
+	if _, err := gredis.Set(cache.GetRefreshKey(), session, ttl); err != nil {
+		return nil, err
+	}
+	access := cache_service.Token{ID: claims.Id}
+	if _, err := gredis.Set(access.GetSessionKey(), refreshToken, ttl); err != nil {
+		return nil, err
+	}
+	sessions := cache_service.Token{ID: user.Username}
+	if err := gredis.SAdd(sessions.GetUserSessionsKey(), claims.Id, ttl); err != nil {
+		return nil, err
+	}
+
+	return &Token{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(setting.AppSetting.JwtExpire / time.Second),
+	}, nil
+}
+
+// RefreshToken 使用 refresh token 换取新的一组 token，旧的 refresh token 只能使用一次，旧的 access token 同时注销
+func RefreshToken(refreshToken string) (*Token, error) {
+	cache := cache_service.Token{ID: refreshToken}
+	key := cache.GetRefreshKey()
+	data, err := gredis.Get(key)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	//并发使用同一个 refresh token 时只有成功删除的那个请求可以继续
+	deleted, err := gredis.Delete(key)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var session refreshSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	//换取新 token 后旧的 access token 立即失效，它已经不在用户的会话中，之后的 RevokeUserTokens 无法再注销它
+	ttl := session.AccessExpiresAt - time.Now().Unix()
+	if session.AccessExpiresAt == 0 {
+		ttl = int64(setting.AppSetting.JwtExpire / time.Second)
+	}
+	if err := revokeAccess(session.AccessID, ttl); err != nil {
+		return nil, err
+	}
+	if err := removeSession(session.Username, session.AccessID); err != nil {
+		return nil, err
+	}
+
+	//重新读取用户，已删除的用户不能再续期，角色变化也会在新 token 中生效
+	user, err := models.GetAuthByUsername(session.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return IssueToken(user)
+}
+
+// RevokeToken 注销 access token 和与它一起签发的 refresh token，refreshToken 不为空时一并删除
+func RevokeToken(claims *util.Claims, refreshToken string) error {
+	if err := revokeAccess(claims.Id, claims.ExpiresAt-time.Now().Unix()); err != nil {
+		return err
+	}
+	if err := deleteSession(claims.Username, claims.Id); err != nil {
+		return err
+	}
+
+	if refreshToken != "" {
+		cache := cache_service.Token{ID: refreshToken}
+		if _, err := gredis.Delete(cache.GetRefreshKey()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevokeUserTokens 注销用户所有的 access token 和 refresh token，修改密码、删除用户后调用
+func RevokeUserTokens(username string) error {
+	sessions := cache_service.Token{ID: username}
+	key := sessions.GetUserSessionsKey()
+	ids, err := gredis.SMembers(key)
+	if err != nil {
+		return err
+	}
+
+	//不知道每个 access token 的过期时间，按最长有效期保留注销记录
+	ttl := int64(setting.AppSetting.JwtExpire / time.Second)
+	for _, id := range ids {
+		if err := revokeAccess(id, ttl); err != nil {
+			return err
+		}
+		if err := deleteSession(username, id); err != nil {
+			return err
+		}
+	}
+
+	_, err = gredis.Delete(key)
+	return err
+}
+
+// IsTokenRevoked 检查 access token 是否已被注销，读取失败时返回错误，调用方应视为已注销
+func IsTokenRevoked(claims *util.Claims) (bool, error) {
+	cache := cache_service.Token{ID: claims.Id}
+	return gredis.Exists(cache.GetRevokedKey())
+}
+
+func revokeAccess(id string, ttl int64) error {
+	if id == "" || ttl <= 0 {
+		return nil
+	}
+
+	cache := cache_service.Token{ID: id}
+	_, err := gredis.Set(cache.GetRevokedKey(), true, int(ttl))
+	return err
+}
+
+// deleteSession 删除 access token 对应的 refresh token 和会话记录
+func deleteSession(username, id string) error {
+	if id == "" {
+		return nil
+	}
+
+	access := cache_service.Token{ID: id}
+	data, err := gredis.Get(access.GetSessionKey())
+	if err != nil && err != redis.ErrNil {
+		return err
+	}
+	var refreshToken string
+	if err == nil && json.Unmarshal(data, &refreshToken) == nil && refreshToken != "" {
+		cache := cache_service.Token{ID: refreshToken}
+		if _, err := gredis.Delete(cache.GetRefreshKey()); err != nil {
+			return err
+		}
+	}
+
+	return removeSession(username, id)
+}
+
+// removeSession 只删除会话记录，refresh token 已经被使用或删除
+func removeSession(username, id string) error {
+	if id == "" {
+		return nil
+	}
+
+	access := cache_service.Token{ID: id}
+	if _, err := gredis.Delete(access.GetSessionKey()); err != nil {
+		return err
+	}
+	sessions := cache_service.Token{ID: username}
+
+	return gredis.SRem(sessions.GetUserSessionsKey(), id)
+}
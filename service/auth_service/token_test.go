@@ -0,0 +1,180 @@
+package auth_service
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/gredis"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/util"
+	"gin-blog/service/cache_service"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setupTest 使用内存数据库和 miniredis
+func setupTest(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+
+	//日志路径相对于当前目录
+	wd, _ := os.Getwd()
+	dir, err := filepath.Rel(wd, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setting.AppSetting.RuntimeRootPath = dir + "/"
+	setting.AppSetting.LogSavePath = ""
+	setting.AppSetting.LogSaveName = "log"
+	setting.AppSetting.LogFileExt = "log"
+	setting.AppSetting.TimeFormat = "20060102"
+	setting.AppSetting.JwtSecret = "test"
+	setting.AppSetting.JwtExpire = time.Hour
+	setting.AppSetting.RefreshTokenExpire = 24 * time.Hour
+	logging.Setup()
+
+	mr := miniredis.RunT(t)
+	setting.RedisSetting = &setting.Redis{Host: mr.Addr(), MaxIdle: 1, MaxActive: 10}
+	gredis.Setup()
+
+	conn, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	setting.DatabaseSetting.TablePrefix = "blog_"
+	models.SetDB(conn)
+	//内存数据库只存在于一个连接中
+	conn.DB().SetMaxOpenConns(1)
+	conn.LogMode(false)
+	if err := conn.AutoMigrate(&models.Auth{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return mr
+}
+
+func addUser(t *testing.T, username, role string) *models.Auth {
+	t.Helper()
+
+	user := &Auth{Username: username, Password: "password", Role: role}
+	if err := user.Add(); err != nil {
+		t.Fatal(err)
+	}
+	auth, err := models.GetAuthByUsername(username)
+	if err != nil || auth.ID == 0 {
+		t.Fatal(auth, err)
+	}
+
+	return auth
+}
+
+func issue(t *testing.T, user *models.Auth) (*Token, *util.Claims) {
+	t.Helper()
+
+	token, err := IssueToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := util.ParseToken(token.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token, claims
+}
+
+func assertRevoked(t *testing.T, claims *util.Claims, want bool) {
+	t.Helper()
+
+	revoked, err := IsTokenRevoked(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked != want {
+		t.Errorf("IsTokenRevoked(%s) = %v, want %v", claims.Id, revoked, want)
+	}
+}
+
+func TestRefreshTokenRevokesOldAccessToken(t *testing.T) {
+	mr := setupTest(t)
+	user := addUser(t, "author", "author")
+	token, claims := issue(t, user)
+
+	refreshed, err := RefreshToken(token.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClaims, err := util.ParseToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertRevoked(t, claims, true)
+	assertRevoked(t, newClaims, false)
+	//注销记录保留到旧 access token 过期为止
+	revoked := cache_service.Token{ID: claims.Id}
+	ttl := mr.TTL(revoked.GetRevokedKey())
+	if ttl <= 0 || ttl > setting.AppSetting.JwtExpire {
+		t.Errorf("revoked ttl = %v", ttl)
+	}
+
+	//refresh token 只能使用一次
+	if _, err := RefreshToken(token.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("RefreshToken() reused = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	setupTest(t)
+	user := addUser(t, "author", "author")
+	token, claims := issue(t, user)
+	_, other := issue(t, user)
+
+	//只传 access token 时同时签发的 refresh token 也会失效
+	if err := RevokeToken(claims, ""); err != nil {
+		t.Fatal(err)
+	}
+	assertRevoked(t, claims, true)
+	assertRevoked(t, other, false)
+	if _, err := RefreshToken(token.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("RefreshToken() after logout = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRevokeUserTokens(t *testing.T) {
+	setupTest(t)
+	user := addUser(t, "author", "author")
+	token, claims := issue(t, user)
+	refreshed, err := RefreshToken(token.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClaims, err := util.ParseToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RevokeUserTokens("author"); err != nil {
+		t.Fatal(err)
+	}
+	assertRevoked(t, claims, true)
+	assertRevoked(t, newClaims, true)
+	if _, err := RefreshToken(refreshed.RefreshToken); err != ErrInvalidRefreshToken {
+		t.Errorf("RefreshToken() after revoke = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+// Redis 不可用时返回错误，由调用方拒绝请求
+func TestIsTokenRevokedFailsClosed(t *testing.T) {
+	mr := setupTest(t)
+	_, claims := issue(t, addUser(t, "author", "author"))
+
+	mr.Close()
+	if _, err := IsTokenRevoked(claims); err == nil {
+		t.Error("IsTokenRevoked() = nil error, want error")
+	}
+}
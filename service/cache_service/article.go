@@ -2,36 +2,57 @@ package cache_service
 
 import (
 	"gin-blog/pkg/err"
+	"gin-blog/pkg/gcache"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 type Article struct {
-	ID    int
-	TagID int
-	State int
+	ID       int
+	TagIDs   []int
+	MatchAll bool
+	State    int
+	//只包含已发布文章的列表与完整列表分开缓存
+	Published bool
 
 	PageNum  int
 	PageSize int
 }
 
-func (a *Article) GetArticleKey() string {
-	return err.CACHE_ARTICLE + "_" + strconv.Itoa(a.ID)
+// 文章详情中包含标签，所以带上标签的版本号，标签修改后详情缓存随之失效
+func (a *Article) GetArticleKey(c gcache.Cache) string {
+	return err.CACHE_ARTICLE + "_" + strconv.Itoa(a.ID) + "_" + GetVersion(c, err.CACHE_TAG)
 }
 
-func (a *Article) GetArticlesKey() string {
+func (a *Article) GetArticlesKey(c gcache.Cache) string {
 	keys := []string{
 		err.CACHE_ARTICLE,
 		"LIST",
+		GetVersion(c, err.CACHE_ARTICLE),
+		GetVersion(c, err.CACHE_TAG),
 	}
 
 	if a.ID > 0 {
 		keys = append(keys, strconv.Itoa(a.ID))
 	}
-	if a.TagID > 0 {
-		keys = append(keys, strconv.Itoa(a.TagID))
+	if len(a.TagIDs) > 0 {
+		//标签的顺序不影响结果，排序后 1,2 和 2,1 使用同一个缓存
+		sorted := append([]int(nil), a.TagIDs...)
+		sort.Ints(sorted)
+		tagIDs := make([]string, 0, len(sorted))
+		for _, tagID := range sorted {
+			tagIDs = append(tagIDs, strconv.Itoa(tagID))
+		}
+		match := "ANY"
+		if a.MatchAll {
+			match = "ALL"
+		}
+		keys = append(keys, "TAGS", strings.Join(tagIDs, ","), match)
 	}
-	if a.State >= 0 {
+	if a.Published {
+		keys = append(keys, "PUBLISHED")
+	} else if a.State >= 0 {
 		keys = append(keys, strconv.Itoa(a.State))
 	}
 	if a.PageNum > 0 {
@@ -42,4 +63,23 @@ func (a *Article) GetArticlesKey() string {
 	}
 
 	return strings.Join(keys, "_")
-}
\ No newline at end of file
+}
+
+// GetCountKey 与列表使用相同的筛选条件，不区分分页
+func (a *Article) GetCountKey(c gcache.Cache) string {
+	count := *a
+	count.PageNum, count.PageSize = 0, 0
+
+	return count.GetArticlesKey(c) + "_COUNT"
+}
+
+// Clear 删除文章详情缓存，并使所有文章列表缓存失效
+func (a *Article) Clear(c gcache.Cache) error {
+	if a.ID > 0 {
+		if e := c.Delete(a.GetArticleKey(c)); e != nil {
+			return e
+		}
+	}
+
+	return BumpVersion(c, err.CACHE_ARTICLE)
+}
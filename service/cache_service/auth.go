@@ -0,0 +1,29 @@
+package cache_service
+
+import (
+	"gin-blog/pkg/err"
+)
+
+type Token struct {
+	ID string
+}
+
+// GetRefreshKey refresh token 对应的用户信息
+func (t *Token) GetRefreshKey() string {
+	return err.CACHE_AUTH_REFRESH + "_" + t.ID
+}
+
+// GetRevokedKey 已注销的 access token，ID 为 token 的 jti
+func (t *Token) GetRevokedKey() string {
+	return err.CACHE_AUTH_REVOKED + "_" + t.ID
+}
+
+// GetSessionKey access token 对应的 refresh token，ID 为 token 的 jti
+func (t *Token) GetSessionKey() string {
+	return err.CACHE_AUTH_SESSION + "_" + t.ID
+}
+
+// GetUserSessionsKey 用户所有未注销的 access token 的 jti 集合，ID 为用户名
+func (t *Token) GetUserSessionsKey() string {
+	return err.CACHE_AUTH_SESSION + "_USER_" + t.ID
+}
@@ -0,0 +1,24 @@
+package cache_service
+
+import (
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/gcache"
+	"strconv"
+	"strings"
+)
+
+type Feed struct {
+	Format string
+	TagID  int
+}
+
+// 订阅中包含文章和标签，任何文章或标签的修改都会使订阅缓存失效
+func (f *Feed) GetFeedKey(c gcache.Cache) string {
+	return strings.Join([]string{
+		err.CACHE_FEED,
+		f.Format,
+		strconv.Itoa(f.TagID),
+		GetVersion(c, err.CACHE_ARTICLE),
+		GetVersion(c, err.CACHE_TAG),
+	}, "_")
+}
@@ -0,0 +1,22 @@
+package cache_service
+
+import (
+	"gin-blog/pkg/err"
+	"gin-blog/pkg/gcache"
+	"strconv"
+	"strings"
+)
+
+type Sitemap struct {
+	Page int
+}
+
+// 站点地图中包含文章和标签，任何文章或标签的修改都会使站点地图缓存失效
+func (s *Sitemap) GetSitemapKey(c gcache.Cache) string {
+	return strings.Join([]string{
+		err.CACHE_SITEMAP,
+		strconv.Itoa(s.Page),
+		GetVersion(c, err.CACHE_ARTICLE),
+		GetVersion(c, err.CACHE_TAG),
+	}, "_")
+}
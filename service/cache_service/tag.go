@@ -2,6 +2,7 @@ package cache_service
 
 import (
 	"gin-blog/pkg/err"
+	"gin-blog/pkg/gcache"
 	"strconv"
 	"strings"
 )
@@ -15,10 +16,11 @@ type Tag struct {
 	PageSize int
 }
 
-func (t *Tag) GetTagsKey() string {
+func (t *Tag) GetTagsKey(c gcache.Cache) string {
 	keys := []string{
 		err.CACHE_TAG,
 		"LIST",
+		GetVersion(c, err.CACHE_TAG),
 	}
 
 	if t.Name != "" {
@@ -36,3 +38,16 @@ func (t *Tag) GetTagsKey() string {
 
 	return strings.Join(keys, "_")
 }
+
+// GetCountKey 与列表使用相同的筛选条件，不区分分页
+func (t *Tag) GetCountKey(c gcache.Cache) string {
+	count := *t
+	count.PageNum, count.PageSize = 0, 0
+
+	return count.GetTagsKey(c) + "_COUNT"
+}
+
+// Clear 使所有标签列表缓存失效，文章缓存中包含标签，也会随之失效
+func (t *Tag) Clear(c gcache.Cache) error {
+	return BumpVersion(c, err.CACHE_TAG)
+}
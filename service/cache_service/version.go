@@ -0,0 +1,40 @@
+package cache_service
+
+import (
+	"gin-blog/pkg/gcache"
+	"gin-blog/pkg/logging"
+	"strconv"
+	"time"
+)
+
+// 列表等无法逐个删除的缓存都带有所属命名空间的版本号，
+// 写操作只需要更新版本号，旧版本的缓存不会再被读取，等待过期即可，避免使用 KEYS 扫描删除
+
+// GetVersion 获取命名空间当前的版本号，不存在时生成一个新的版本号
+func GetVersion(c gcache.Cache, namespace string) string {
+	key := getVersionKey(namespace)
+	if data, err := c.Get(key); err == nil && len(data) > 0 {
+		return string(data)
+	}
+
+	//版本号丢失时不能回退到固定的初始值，否则可能读到之前写入的旧缓存
+	version := newVersion()
+	if err := c.Set(key, []byte(version), 0); err != nil {
+		logging.Warn(err)
+	}
+
+	return version
+}
+
+// BumpVersion 更新命名空间的版本号，使该命名空间下的缓存全部失效
+func BumpVersion(c gcache.Cache, namespace string) error {
+	return c.Set(getVersionKey(namespace), []byte(newVersion()), 0)
+}
+
+func getVersionKey(namespace string) string {
+	return "VERSION_" + namespace
+}
+
+func newVersion() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36)
+}
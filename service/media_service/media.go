@@ -0,0 +1,194 @@
+package media_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"gin-blog/models"
+	"gin-blog/pkg/imaging"
+	"gin-blog/pkg/storage"
+	"gin-blog/pkg/upload"
+	"github.com/jinzhu/gorm"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+)
+
+type Media struct {
+	ID        int
+	TagIDs    []int
+	Filename  string
+	CreatedBy string
+	MimeType  string
+	//按文件名或哈希搜索
+	Query string
+
+	PageNum  int
+	PageSize int
+}
+
+// Detail 媒体文件详情，包括缩放版本和引用该文件的文章
+type Detail struct {
+	*models.Media
+	Variants   []*upload.Variant `json:"variants"`
+	ArticleIDs []int             `json:"article_ids"`
+}
+
+// Upload 保存上传的图片，内容相同的图片已经存在时直接使用已有的文件并增加上传次数。
+// JPEG 中的 GPS 信息在计算哈希之前清除，清除后内容相同的图片视为同一张
+func (m *Media) Upload(r io.Reader) (*models.Media, []*upload.Variant, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	imaging.StripGPS(data)
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	media, err := models.GetMediaByHash(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if media.ID > 0 {
+		return reuse(media, data)
+	}
+
+	name := upload.GetImageName(hash, upload.GetImageExt(data))
+	img, err := upload.SaveImage(data, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	media = &models.Media{
+		Hash:        hash,
+		Name:        name,
+		MimeType:    http.DetectContentType(data),
+		Size:        len(data),
+		Width:       img.Width,
+		Height:      img.Height,
+		Filename:    m.Filename,
+		UploadCount: 1,
+		CreatedBy:   m.CreatedBy,
+	}
+	if err := models.AddMedia(media); err != nil {
+		//同时上传相同内容时另一个请求已经写入了记录，保存的文件相同，直接使用该记录
+		existing, e := models.GetMediaByHash(hash)
+		if e != nil || existing.ID == 0 {
+			return nil, nil, err
+		}
+		if err := models.IncrMediaUploadCount(existing.ID); err != nil {
+			return nil, nil, err
+		}
+		existing.UploadCount++
+		return existing, img.Variants, nil
+	}
+
+	return media, img.Variants, nil
+}
+
+// reuse 使用已经保存的图片，文件被手动删除时重新保存
+func reuse(media *models.Media, data []byte) (*models.Media, []*upload.Variant, error) {
+	var variants []*upload.Variant
+	_, err := storage.Default.Stat(upload.GetImagePath() + media.Name)
+	if err == storage.ErrNotExist {
+		img, err := upload.SaveImage(data, media.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		variants = img.Variants
+	} else if err != nil {
+		return nil, nil, err
+	} else if variants, err = upload.GetImageVariants(media.Name); err != nil {
+		return nil, nil, err
+	}
+
+	if err := models.IncrMediaUploadCount(media.ID); err != nil {
+		return nil, nil, err
+	}
+	media.UploadCount++
+
+	return media, variants, nil
+}
+
+func (m *Media) GetAll() ([]*models.Media, error) {
+	list, err := models.GetMediaList(m.PageNum, m.PageSize, m.getMaps(), m.scopes()...)
+	if err != nil {
+		return nil, err
+	}
+	for _, media := range list {
+		media.Url = upload.GetImageFullUrl(media.Name)
+	}
+
+	return list, nil
+}
+
+func (m *Media) Count() (int, error) {
+	return models.GetMediaTotal(m.getMaps(), m.scopes()...)
+}
+
+// Get 不存在时返回 nil
+func (m *Media) Get() (*Detail, error) {
+	media, err := models.GetMedia(m.ID)
+	if err != nil || media.ID == 0 {
+		return nil, err
+	}
+	media.Url = upload.GetImageFullUrl(media.Name)
+
+	variants, err := upload.GetImageVariants(media.Name)
+	if err != nil {
+		return nil, err
+	}
+	articleIDs, err := models.GetMediaArticleIDs(media.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Detail{Media: media, Variants: variants, ArticleIDs: articleIDs}, nil
+}
+
+func (m *Media) EditTags() error {
+	return models.EditMediaTags(m.ID, m.TagIDs)
+}
+
+// Delete 删除原图、所有缩放版本和媒体文件记录
+func (m *Media) Delete(media *models.Media) error {
+	if err := removeFiles(media.Name); err != nil {
+		return err
+	}
+
+	return models.DeleteMedia(media.ID)
+}
+
+func (m *Media) getMaps() map[string]interface{} {
+	maps := make(map[string]interface{})
+	if m.CreatedBy != "" {
+		maps["created_by"] = m.CreatedBy
+	}
+	if m.MimeType != "" {
+		maps["mime_type"] = m.MimeType
+	}
+
+	return maps
+}
+
+func (m *Media) scopes() []func(*gorm.DB) *gorm.DB {
+	return []func(*gorm.DB) *gorm.DB{models.WithMediaQuery(m.Query), models.WithMediaTagIDs(m.TagIDs)}
+}
+
+// removeFiles 缩放版本与原图在同一目录下，文件名都以哈希开头
+func removeFiles(name string) error {
+	objects, err := storage.Default.List(upload.GetImagePath() + strings.TrimSuffix(name, path.Ext(name)))
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		if err := storage.Default.Delete(object.Key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,415 @@
+package media_service
+
+import (
+	"bytes"
+	"gin-blog/models"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"gin-blog/pkg/upload"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setupTest 使用内存数据库和临时目录中的本地存储
+func setupTest(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	//日志路径相对于当前目录
+	wd, _ := os.Getwd()
+	dir, err := filepath.Rel(wd, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setting.AppSetting.RuntimeRootPath = dir + "/"
+	setting.AppSetting.LogSavePath = ""
+	setting.AppSetting.LogSaveName = "log"
+	setting.AppSetting.LogFileExt = "log"
+	setting.AppSetting.TimeFormat = "20060102"
+	setting.AppSetting.ImageSavePath = "upload/images/"
+	logging.Setup()
+	storage.Default = storage.NewLocal(t.TempDir(), "http://localhost:8080")
+
+	conn, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	setting.DatabaseSetting.TablePrefix = "blog_"
+	models.SetDB(conn)
+	//内存数据库只存在于一个连接中
+	conn.DB().SetMaxOpenConns(1)
+	conn.LogMode(false)
+	err = conn.AutoMigrate(&models.Article{}, &models.ArticleRevision{}, &models.Tag{}, &models.Media{}, &models.MediaTag{}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	//与 blog.sql 一致，hash 上有唯一索引
+	if err := conn.Model(&models.Media{}).AddUniqueIndex("hash", "hash").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func TestMediaQuery(t *testing.T) {
+	setupTest(t)
+	for i, filename := range []string{"a_b.png", "axb.png", "100%.png", "100x.png", "a!b.png"} {
+		media := &models.Media{Hash: strings.Repeat(string(rune('a'+i)), 64), Name: filename, Filename: filename}
+		if err := models.AddMedia(media); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		q    string
+		want []string
+	}{
+		{"a_b", []string{"a_b.png"}},
+		{"100%", []string{"100%.png"}},
+		{"a!b", []string{"a!b.png"}},
+		{"x", []string{"100x.png", "axb.png"}},
+		//哈希只按开头匹配
+		{"bbb", []string{"axb.png"}},
+		{"_", []string{"a_b.png"}},
+	}
+	for _, tt := range tests {
+		list, err := (&Media{Query: tt.q, PageSize: 10}).GetAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, media := range list {
+			got = append(got, media.Filename)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("GetAll(q=%q) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+// 文章修改后不再引用的图片仍被历史版本引用，恢复历史版本后需要能正常显示
+func TestMediaReferencedByRevision(t *testing.T) {
+	setupTest(t)
+	hash := strings.Repeat("ab", 32)
+	id, err := models.AddArticle(map[string]interface{}{
+		"title":           "title",
+		"desc":            "desc",
+		"content":         "![](http://localhost:8080/upload/images/ab/ab/" + hash + "_thumb.jpg)",
+		"created_by":      "test",
+		"state":           1,
+		"cover_image_url": "",
+		"publish_at":      0,
+		"unpublish_at":    0,
+		"tag_ids":         []int{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.EditArticle(id, map[string]interface{}{"content": "no images"}); err != nil {
+		t.Fatal(err)
+	}
+
+	referenced, err := referencedMedia()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !referenced[hash] {
+		t.Errorf("referencedMedia() = %v, want %s", referenced, hash)
+	}
+
+	ids, err := models.GetMediaArticleIDs(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != id {
+		t.Errorf("GetMediaArticleIDs() = %v, want [%d]", ids, id)
+	}
+}
+
+// pngData 创建 width x height 的 PNG，seed 不同时内容不同
+func pngData(t *testing.T, width, height int, seed uint8) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	img.SetNRGBA(0, 0, color.NRGBA{R: seed, A: 255})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func uploadImage(t *testing.T, filename string, data []byte) (*models.Media, []*upload.Variant) {
+	t.Helper()
+
+	media, variants, err := (&Media{Filename: filename, CreatedBy: "test"}).Upload(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return media, variants
+}
+
+func storedFiles(t *testing.T) []string {
+	t.Helper()
+
+	objects, err := storage.Default.List(setting.AppSetting.ImageSavePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func setupUploadTest(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	conn := setupTest(t)
+	imageSetting := *setting.ImageSetting
+	t.Cleanup(func() { *setting.ImageSetting = imageSetting })
+	setting.ImageSetting.Variants = []string{"thumb:10"}
+	setting.ImageSetting.Format = ""
+
+	return conn
+}
+
+func TestUploadSameContent(t *testing.T) {
+	setupUploadTest(t)
+	data := pngData(t, 20, 20, 1)
+
+	first, _ := uploadImage(t, "a.png", data)
+	second, variants := uploadImage(t, "b.png", data)
+	if second.ID != first.ID || second.UploadCount != 2 {
+		t.Errorf("second upload = %+v, want id %d and upload_count 2", second, first.ID)
+	}
+	//文件名保留第一次上传时的名称
+	if second.Filename != "a.png" {
+		t.Errorf("Filename = %q, want a.png", second.Filename)
+	}
+	if len(variants) != 1 || variants[0].Name != "thumb" {
+		t.Errorf("variants = %+v", variants)
+	}
+
+	media, err := models.GetMedia(first.ID)
+	if err != nil || media.UploadCount != 2 {
+		t.Errorf("GetMedia() = %+v, %v, want upload_count 2", media, err)
+	}
+	total, err := (&Media{}).Count()
+	if err != nil || total != 1 {
+		t.Errorf("Count() = %d, %v, want 1", total, err)
+	}
+	if files := storedFiles(t); len(files) != 2 {
+		t.Errorf("files = %v, want the image and its thumbnail", files)
+	}
+}
+
+func TestUploadDifferentContent(t *testing.T) {
+	setupUploadTest(t)
+
+	first, _ := uploadImage(t, "a.png", pngData(t, 20, 20, 1))
+	second, _ := uploadImage(t, "a.png", pngData(t, 20, 20, 2))
+	if second.ID == first.ID || second.Hash == first.Hash || second.Name == first.Name {
+		t.Errorf("uploads = %+v, %+v, want two media", first, second)
+	}
+	if first.UploadCount != 1 || second.UploadCount != 1 {
+		t.Errorf("upload_count = %d, %d, want 1, 1", first.UploadCount, second.UploadCount)
+	}
+	if files := storedFiles(t); len(files) != 4 {
+		t.Errorf("files = %v, want two images and their thumbnails", files)
+	}
+}
+
+// 文件被手动删除后再次上传相同的内容，重新保存文件和缩放版本
+func TestUploadDeletedFile(t *testing.T) {
+	setupUploadTest(t)
+	data := pngData(t, 20, 20, 1)
+
+	first, _ := uploadImage(t, "a.png", data)
+	for _, key := range storedFiles(t) {
+		if err := storage.Default.Delete(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	second, variants := uploadImage(t, "b.png", data)
+	if second.ID != first.ID || second.UploadCount != 2 {
+		t.Errorf("second upload = %+v, want id %d and upload_count 2", second, first.ID)
+	}
+	if len(variants) != 1 {
+		t.Errorf("variants = %+v, want thumb", variants)
+	}
+	want := []string{
+		setting.AppSetting.ImageSavePath + first.Name,
+		variants[0].SaveUrl,
+	}
+	sort.Strings(want)
+	if files := storedFiles(t); strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+}
+
+// 同时上传相同的内容时，另一个请求在查询之后、写入之前写入了记录
+func TestUploadConflict(t *testing.T) {
+	conn := setupUploadTest(t)
+	data := pngData(t, 20, 20, 1)
+
+	var existing *models.Media
+	conn.Callback().Create().Before("gorm:begin_transaction").Register("test:concurrent_upload", func(scope *gorm.Scope) {
+		media, ok := scope.Value.(*models.Media)
+		if !ok || existing != nil {
+			return
+		}
+		existing = &models.Media{Hash: media.Hash, Name: media.Name, Filename: "other.png", UploadCount: 1}
+		if err := scope.NewDB().Create(existing).Error; err != nil {
+			t.Error(err)
+		}
+	})
+	t.Cleanup(func() { conn.Callback().Create().Remove("test:concurrent_upload") })
+
+	media, variants := uploadImage(t, "a.png", data)
+	if existing == nil || media.ID != existing.ID || media.UploadCount != 2 || media.Filename != "other.png" {
+		t.Errorf("Upload() = %+v, want the concurrently added media %+v with upload_count 2", media, existing)
+	}
+	if len(variants) != 1 {
+		t.Errorf("variants = %+v, want thumb", variants)
+	}
+	if total, err := (&Media{}).Count(); err != nil || total != 1 {
+		t.Errorf("Count() = %d, %v, want 1", total, err)
+	}
+}
+
+// addTaggedMedia 添加媒体文件记录并设置标签
+func addTaggedMedia(t *testing.T, filename string, tagIDs []int) *models.Media {
+	t.Helper()
+
+	media := &models.Media{Hash: strings.Repeat(filename[:1], 64), Name: filename, Filename: filename}
+	if err := models.AddMedia(media); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Media{ID: media.ID, TagIDs: tagIDs}).EditTags(); err != nil {
+		t.Fatal(err)
+	}
+
+	return media
+}
+
+func addMediaTag(t *testing.T, name string) int {
+	t.Helper()
+
+	if err := models.AddTag(name, 1, "test"); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := models.GetTagsByNames([]string{name})
+	if err != nil || len(tags) != 1 {
+		t.Fatal(tags, err)
+	}
+
+	return tags[0].ID
+}
+
+func TestMediaTagFilter(t *testing.T) {
+	setupTest(t)
+	goID, webID := addMediaTag(t, "go"), addMediaTag(t, "web")
+	addTaggedMedia(t, "a.png", []int{goID})
+	addTaggedMedia(t, "b.png", []int{goID, webID})
+	addTaggedMedia(t, "c.png", []int{webID})
+	addTaggedMedia(t, "d.png", nil)
+
+	tests := []struct {
+		tagIDs []int
+		want   []string
+	}{
+		{[]int{goID}, []string{"a.png", "b.png"}},
+		{[]int{webID}, []string{"b.png", "c.png"}},
+		{[]int{goID, webID}, []string{"a.png", "b.png", "c.png"}},
+		{nil, []string{"a.png", "b.png", "c.png", "d.png"}},
+	}
+	for _, tt := range tests {
+		list, err := (&Media{TagIDs: tt.tagIDs, PageSize: 10}).GetAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, media := range list {
+			got = append(got, media.Filename)
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("GetAll(tags=%v) = %v, want %v", tt.tagIDs, got, tt.want)
+		}
+	}
+}
+
+// 清空标签回收站只删除被删除标签的关联
+func TestCleanAllTagKeepsMediaTags(t *testing.T) {
+	setupTest(t)
+	deletedID, keptID, otherID := addMediaTag(t, "deleted"), addMediaTag(t, "kept"), addMediaTag(t, "other")
+	media := addTaggedMedia(t, "a.png", []int{deletedID, keptID, otherID})
+
+	if err := models.DeleteTag(deletedID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := models.CleanAllTag(int(time.Now().Unix()) + 1); err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err := (&Media{ID: media.ID}).Get()
+	if err != nil || detail == nil {
+		t.Fatal(detail, err)
+	}
+	var got []string
+	for _, tag := range detail.Tags {
+		got = append(got, tag.Name)
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != "kept,other" {
+		t.Errorf("tags = %v, want [kept other]", got)
+	}
+}
+
+// 彻底删除标签时同时删除媒体文件与它的关联
+func TestPurgeTagRemovesMediaTags(t *testing.T) {
+	conn := setupTest(t)
+	purgedID, keptID := addMediaTag(t, "purged"), addMediaTag(t, "kept")
+	media := addTaggedMedia(t, "a.png", []int{purgedID, keptID})
+
+	if err := models.DeleteTag(purgedID); err != nil {
+		t.Fatal(err)
+	}
+	if err := models.PurgeTag(purgedID); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := conn.Model(&models.MediaTag{}).Where("tag_id = ?", purgedID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("media tags of purged tag = %d, want 0", count)
+	}
+	detail, err := (&Media{ID: media.ID}).Get()
+	if err != nil || detail == nil {
+		t.Fatal(detail, err)
+	}
+	if len(detail.Tags) != 1 || detail.Tags[0].Name != "kept" {
+		t.Errorf("tags = %v, want [kept]", detail.Tags)
+	}
+}
@@ -0,0 +1,173 @@
+package media_service
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"gin-blog/pkg/storage"
+	"gin-blog/pkg/upload"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 查找引用时每批读取的文章和历史版本数
+const orphanBatchSize = 200
+
+// Orphan 没有被任何文章的封面或正文引用的文件
+type Orphan struct {
+	Key     string `json:"key"`
+	Url     string `json:"url"`
+	Size    int64  `json:"size"`
+	ModTime int    `json:"mod_time"`
+	//对应的媒体文件记录，没有记录时为 0
+	MediaID int `json:"media_id"`
+}
+
+// FindOrphans 查找超过保留期限且没有被引用的上传文件。
+// 原图和缩放版本的文件名都以图片哈希开头，文章引用其中任意一个时同一张图片的所有文件都视为被引用
+func FindOrphans() ([]*Orphan, error) {
+	referenced, err := referencedMedia()
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := storage.Default.List(upload.GetImagePath())
+	if err != nil {
+		return nil, err
+	}
+
+	//保留期限内上传的文件可能还没有写进文章
+	deadline := time.Now().AddDate(0, 0, -setting.AppSetting.MediaOrphanDays)
+	var (
+		orphans []*Orphan
+		hashes  []string
+	)
+	for _, object := range objects {
+		base := mediaBase(object.Key)
+		if referenced[base] || object.ModTime.After(deadline) {
+			continue
+		}
+
+		orphans = append(orphans, &Orphan{
+			Key:     object.Key,
+			Url:     storage.Default.URL(object.Key),
+			Size:    object.Size,
+			ModTime: int(object.ModTime.Unix()),
+		})
+		hashes = append(hashes, base)
+	}
+
+	list, err := models.GetMediaByHashes(hashes)
+	if err != nil {
+		return nil, err
+	}
+	medias := make(map[string]*models.Media, len(list))
+	for _, media := range list {
+		medias[media.Hash] = media
+	}
+
+	//重复上传会更新媒体文件记录的修改时间，最近上传过的文件同样保留
+	result := orphans[:0]
+	for _, orphan := range orphans {
+		media, ok := medias[mediaBase(orphan.Key)]
+		if ok && int64(media.ModifiedOn) > deadline.Unix() {
+			continue
+		}
+		if ok {
+			orphan.MediaID = media.ID
+		}
+		result = append(result, orphan)
+	}
+
+	return result, nil
+}
+
+// CleanOrphans 记录没有被引用的上传文件，配置了 MediaOrphanRemove 时删除文件和对应的媒体文件记录，供定时任务调用
+func CleanOrphans() error {
+	orphans, err := FindOrphans()
+	if err != nil {
+		return err
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	if !setting.AppSetting.MediaOrphanRemove {
+		for _, orphan := range orphans {
+			logging.Info("orphaned upload", orphan.Key)
+		}
+		logging.Info("found", len(orphans), "orphaned uploads")
+		return nil
+	}
+
+	mediaIDs := make(map[int]bool)
+	for _, orphan := range orphans {
+		if err := storage.Default.Delete(orphan.Key); err != nil {
+			return err
+		}
+		if orphan.MediaID > 0 {
+			mediaIDs[orphan.MediaID] = true
+		}
+	}
+	for id := range mediaIDs {
+		if err := models.DeleteMedia(id); err != nil {
+			return err
+		}
+	}
+	logging.Info("removed", len(orphans), "orphaned uploads,", len(mediaIDs), "media")
+
+	return nil
+}
+
+// referencedMedia 读取所有文章（包括回收站中的文章）和历史版本的封面和正文，返回其中引用的图片。
+// 文章恢复为历史版本后会重新引用其中的图片，所以历史版本中的引用同样要保留
+func referencedMedia() (map[string]bool, error) {
+	pattern := regexp.MustCompile(regexp.QuoteMeta(upload.GetImagePath()) + `[A-Za-z0-9_./-]+`)
+	referenced := make(map[string]bool)
+	add := func(coverImageUrl, content string) {
+		for _, key := range pattern.FindAllString(coverImageUrl+"\n"+content, -1) {
+			referenced[mediaBase(key)] = true
+		}
+	}
+
+	for offset := 0; ; offset += orphanBatchSize {
+		articles, err := models.GetArticleContents(offset, orphanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, article := range articles {
+			add(article.CoverImageUrl, article.Content)
+		}
+		if len(articles) < orphanBatchSize {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += orphanBatchSize {
+		revisions, err := models.GetArticleRevisionContents(offset, orphanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, revision := range revisions {
+			add(revision.CoverImageUrl, revision.Content)
+		}
+		if len(revisions) < orphanBatchSize {
+			return referenced, nil
+		}
+	}
+}
+
+// mediaBase 去掉目录、扩展名和缩放版本的后缀，按内容保存的图片即为图片哈希
+func mediaBase(key string) string {
+	base := path.Base(key)
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if i := strings.Index(base, "_"); i > 0 {
+		base = base[:i]
+	}
+
+	return base
+}
@@ -0,0 +1,256 @@
+package tag_service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gin-blog/models"
+	"github.com/360EntSecGroup-Skylar/excelize"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidImportFile 导入文件无法解析或缺少名称列
+var ErrInvalidImportFile = errors.New("invalid import file")
+
+// 导入文件的表头，同时支持导出文件中的中文表头和 JSON 导出的字段名
+var importColumns = map[string]string{
+	"名称":         "name",
+	"name":       "name",
+	"创建人":        "created_by",
+	"created_by": "created_by",
+	"状态":         "state",
+	"state":      "state",
+}
+
+// ImportOptions 导入标签的方式
+type ImportOptions struct {
+	//名称已存在时更新标签的状态，否则跳过
+	Update bool
+	//任何一行失败时不导入任何标签
+	Atomic bool
+	//只检查并返回导入结果，不保存
+	DryRun bool
+}
+
+// ImportReport 导入结果，Committed 表示修改是否已经保存
+type ImportReport struct {
+	DryRun    bool        `json:"dry_run"`
+	Committed bool        `json:"committed"`
+	Created   []ImportRow `json:"created"`
+	Updated   []ImportRow `json:"updated"`
+	Skipped   []ImportRow `json:"skipped"`
+	Failed    []ImportRow `json:"failed"`
+}
+
+// ImportRow 导入文件中的一行，Row 为文件中的行号，表头为第1行
+type ImportRow struct {
+	Row    int    `json:"row"`
+	Name   string `json:"name"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type importRecord struct {
+	row       int
+	name      string
+	createdBy string
+	state     string
+}
+
+// Import 从 xlsx 或 csv 文件导入标签，文件第一行为表头，必须包含名称列，创建人、状态列可选。
+// 创建人为空时使用 t.CreatedBy，更新已有标签时 t.CreatedBy 作为修改人
+func (t *Tag) Import(r io.Reader, format string, opts ImportOptions) (*ImportReport, error) {
+	var (
+		rows [][]string
+		err  error
+	)
+	switch format {
+	case "xlsx":
+		rows, err = readXlsx(r)
+	case "csv":
+		rows, err = readCSV(r)
+	default:
+		err = fmt.Errorf("%w: unsupported format %q", ErrInvalidImportFile, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := parseRecords(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{
+		DryRun:  opts.DryRun,
+		Created: []ImportRow{},
+		Updated: []ImportRow{},
+		Skipped: []ImportRow{},
+		Failed:  []ImportRow{},
+	}
+
+	var (
+		changes []*models.TagChange
+		rowsOf  []ImportRow
+	)
+	seen := make(map[string]int)
+	for _, record := range records {
+		row := ImportRow{Row: record.row, Name: record.name}
+
+		state, reason := validRecord(record)
+		if reason == "" {
+			if first, ok := seen[strings.ToLower(record.name)]; ok {
+				reason = fmt.Sprintf("与第%d行的名称重复", first)
+			}
+		}
+		if reason != "" {
+			row.Reason = reason
+			report.Failed = append(report.Failed, row)
+			continue
+		}
+		seen[strings.ToLower(record.name)] = record.row
+
+		exists, err := models.ExistTagByName(record.name)
+		if err != nil {
+			return nil, err
+		}
+		if exists && !opts.Update {
+			row.Reason = "标签已存在"
+			report.Skipped = append(report.Skipped, row)
+			continue
+		}
+
+		user := record.createdBy
+		if user == "" || exists {
+			user = t.CreatedBy
+		}
+		changes = append(changes, &models.TagChange{Name: record.name, State: state, User: user, Update: exists})
+		rowsOf = append(rowsOf, row)
+	}
+
+	//整体导入时已经有校验失败的行，只预览其余行的结果
+	commit := !opts.DryRun && !(opts.Atomic && len(report.Failed) > 0)
+	report.Committed, err = models.ApplyTagChanges(changes, opts.Atomic, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, change := range changes {
+		row := rowsOf[i]
+		switch {
+		case change.Err != nil:
+			row.Reason = change.Err.Error()
+			report.Failed = append(report.Failed, row)
+		case change.Update:
+			report.Updated = append(report.Updated, row)
+		default:
+			report.Created = append(report.Created, row)
+		}
+	}
+
+	if report.Committed && len(report.Created)+len(report.Updated) > 0 {
+		t.clearCache()
+	}
+	return report, nil
+}
+
+// 校验一行数据，返回解析后的状态和失败原因，校验通过时原因为空
+func validRecord(record importRecord) (int, string) {
+	if record.name == "" {
+		return 0, "名称不能为空"
+	}
+	if utf8.RuneCountInString(record.name) > 100 {
+		return 0, "名称最长为100字符"
+	}
+	if utf8.RuneCountInString(record.createdBy) > 100 {
+		return 0, "创建人最长为100字符"
+	}
+
+	switch record.state {
+	case "", "1", "启用":
+		return 1, ""
+	case "0", "禁用":
+		return 0, ""
+	}
+
+	return 0, "状态只允许0或1"
+}
+
+// 根据表头找到各列的位置，缺少的单元格按空字符串处理
+func parseRecords(rows [][]string) ([]importRecord, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidImportFile)
+	}
+
+	index := make(map[string]int)
+	for i, title := range rows[0] {
+		title = strings.TrimPrefix(title, "\uFEFF")
+		if column, ok := importColumns[strings.ToLower(strings.TrimSpace(title))]; ok {
+			if _, dup := index[column]; !dup {
+				index[column] = i
+			}
+		}
+	}
+	if _, ok := index["name"]; !ok {
+		return nil, fmt.Errorf("%w: missing name column", ErrInvalidImportFile)
+	}
+
+	cell := func(row []string, column string) string {
+		i, ok := index[column]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	records := make([]importRecord, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		record := importRecord{
+			row:       i + 2,
+			name:      cell(row, "name"),
+			createdBy: cell(row, "created_by"),
+			state:     cell(row, "state"),
+		}
+		//跳过空行
+		if record.name == "" && record.createdBy == "" && record.state == "" {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// 优先读取导出文件使用的“标签信息”工作表，不存在时读取第一个工作表
+func readXlsx(r io.Reader) ([][]string, error) {
+	xlsx, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	sheet := "标签信息"
+	if xlsx.GetSheetIndex(sheet) == 0 {
+		first := 0
+		for index, name := range xlsx.GetSheetMap() {
+			if first == 0 || index < first {
+				first, sheet = index, name
+			}
+		}
+	}
+
+	return xlsx.GetRows(sheet), nil
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportFile, err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,199 @@
+package tag_service
+
+import (
+	"errors"
+	"fmt"
+	"gin-blog/models"
+	"strings"
+	"testing"
+)
+
+// 把导入结果中的行格式化为“行号 名称 原因”
+func importRows(rows []ImportRow) []string {
+	got := make([]string, 0, len(rows))
+	for _, row := range rows {
+		got = append(got, strings.TrimSpace(fmt.Sprintf("%d %s %s", row.Row, row.Name, row.Reason)))
+	}
+
+	return got
+}
+
+// 返回所有标签的“名称:状态:创建人”
+func tagStates(t *testing.T) []string {
+	t.Helper()
+
+	tags, err := (&Tag{State: -1}).GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		states = append(states, fmt.Sprintf("%s:%d:%s", tag.Name, tag.State, tag.CreatedBy))
+	}
+
+	return states
+}
+
+func TestImport(t *testing.T) {
+	long := strings.Repeat("标", 101)
+	tests := []struct {
+		name      string
+		input     string
+		opts      ImportOptions
+		committed bool
+		created   []string
+		updated   []string
+		skipped   []string
+		failed    []string
+		//导入后的所有标签，导入前只有启用的标签 go
+		tags []string
+	}{
+		{
+			name:      "invalid rows",
+			input:     "名称,创建人,状态\n,alice,1\n" + long + ",,1\nrust," + long + ",1\njava,,2\nkotlin,,禁用\n",
+			committed: true,
+			created:   []string{"6 kotlin"},
+			failed:    []string{"2  名称不能为空", "3 " + long + " 名称最长为100字符", "4 rust 创建人最长为100字符", "5 java 状态只允许0或1"},
+			tags:      []string{"go:1:test", "kotlin:0:admin"},
+		},
+		{
+			name:      "duplicate names ignore case",
+			input:     "名称\nRust\nrust\nRUST\n",
+			committed: true,
+			created:   []string{"2 Rust"},
+			failed:    []string{"3 rust 与第2行的名称重复", "4 RUST 与第2行的名称重复"},
+			tags:      []string{"go:1:test", "Rust:1:admin"},
+		},
+		{
+			name:      "skip existing",
+			input:     "名称,状态\ngo,0\njava,1\n",
+			committed: true,
+			created:   []string{"3 java"},
+			skipped:   []string{"2 go 标签已存在"},
+			tags:      []string{"go:1:test", "java:1:admin"},
+		},
+		{
+			name:      "update existing",
+			input:     "名称,状态\ngo,0\njava,1\n",
+			opts:      ImportOptions{Update: true},
+			committed: true,
+			created:   []string{"3 java"},
+			updated:   []string{"2 go"},
+			tags:      []string{"go:0:test", "java:1:admin"},
+		},
+		{
+			name:    "atomic with invalid row",
+			input:   "名称,状态\njava,1\nrust,2\n",
+			opts:    ImportOptions{Atomic: true},
+			created: []string{"2 java"},
+			failed:  []string{"3 rust 状态只允许0或1"},
+			tags:    []string{"go:1:test"},
+		},
+		{
+			name:    "atomic with failed insert",
+			input:   "名称\njava\nfail\n",
+			opts:    ImportOptions{Atomic: true},
+			created: []string{"2 java"},
+			failed:  []string{"3 fail insert failed"},
+			tags:    []string{"go:1:test"},
+		},
+		{
+			name:      "failed insert",
+			input:     "名称\njava\nfail\n",
+			committed: true,
+			created:   []string{"2 java"},
+			failed:    []string{"3 fail insert failed"},
+			tags:      []string{"go:1:test", "java:1:admin"},
+		},
+		{
+			name:    "dry run",
+			input:   "名称,状态\ngo,0\njava,1\n",
+			opts:    ImportOptions{Update: true, DryRun: true},
+			created: []string{"3 java"},
+			updated: []string{"2 go"},
+			tags:    []string{"go:1:test"},
+		},
+		{
+			name:      "json headers",
+			input:     "created_by, State ,Name\nalice,0,java\n,1,rust\n",
+			committed: true,
+			created:   []string{"2 java", "3 rust"},
+			tags:      []string{"go:1:test", "java:0:alice", "rust:1:admin"},
+		},
+		{
+			name:      "utf-8 bom",
+			input:     "\uFEFF名称,状态\njava,禁用\n",
+			committed: true,
+			created:   []string{"2 java"},
+			tags:      []string{"go:1:test", "java:0:admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := setupTest(t)
+			if err := models.AddTag("go", 1, "test"); err != nil {
+				t.Fatal(err)
+			}
+			//模拟写入数据库失败
+			err := conn.Exec(`CREATE TRIGGER fail_tag BEFORE INSERT ON blog_tag WHEN NEW.name = 'fail'
+				BEGIN SELECT RAISE(ABORT, 'insert failed'); END`).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tag := Tag{CreatedBy: "admin"}
+			report, err := tag.Import(strings.NewReader(tt.input), "csv", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.DryRun != tt.opts.DryRun || report.Committed != tt.committed {
+				t.Errorf("dry_run = %v, committed = %v, want %v, %v", report.DryRun, report.Committed, tt.opts.DryRun, tt.committed)
+			}
+			for _, result := range []struct {
+				name string
+				got  []ImportRow
+				want []string
+			}{
+				{"created", report.Created, tt.created},
+				{"updated", report.Updated, tt.updated},
+				{"skipped", report.Skipped, tt.skipped},
+				{"failed", report.Failed, tt.failed},
+			} {
+				if got := importRows(result.got); strings.Join(got, "|") != strings.Join(result.want, "|") {
+					t.Errorf("%s = %q, want %q", result.name, got, result.want)
+				}
+			}
+			if got := tagStates(t); strings.Join(got, ",") != strings.Join(tt.tags, ",") {
+				t.Errorf("tags = %v, want %v", got, tt.tags)
+			}
+		})
+	}
+}
+
+func TestImportInvalidFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format string
+	}{
+		{"empty", "", "csv"},
+		{"missing name column", "创建人,状态\nalice,1\n", "csv"},
+		{"malformed csv", "名称\n\"java\n", "csv"},
+		{"not xlsx", "名称\njava\n", "xlsx"},
+		{"unsupported format", "名称\njava\n", "json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+
+			tag := Tag{CreatedBy: "admin"}
+			report, err := tag.Import(strings.NewReader(tt.input), tt.format, ImportOptions{})
+			if !errors.Is(err, ErrInvalidImportFile) {
+				t.Errorf("Import() = %+v, %v, want ErrInvalidImportFile", report, err)
+			}
+		})
+	}
+}
@@ -1,21 +1,25 @@
 package tag_service
 
 import (
-	"encoding/json"
 	"gin-blog/models"
 	"gin-blog/pkg/export"
-	"gin-blog/pkg/gredis"
+	"gin-blog/pkg/gcache"
 	"gin-blog/pkg/logging"
 	"gin-blog/service/cache_service"
-	"github.com/360EntSecGroup-Skylar/excelize"
-	"github.com/tealeg/xlsx"
-	"io"
-	"strconv"
+	"strings"
 	"time"
 )
 
+var store gcache.Cache = gcache.NewNone()
+
+// SetCache 设置标签缓存使用的后端，未设置时不缓存
+func SetCache(c gcache.Cache) {
+	store = c
+}
+
 type Tag struct {
 	ID         int
+	IDs        []int
 	Name       string
 	CreatedBy  string
 	ModifiedBy string
@@ -33,8 +37,78 @@ func (t *Tag) ExistByID() (bool, error) {
 	return models.ExistTagByID(t.ID)
 }
 
+func (t *Tag) ExistByIDs() (bool, error) {
+	return models.ExistTagsByIDs(t.IDs)
+}
+
 func (t *Tag) Add() error {
-	return models.AddTag(t.Name, t.State, t.CreatedBy)
+	if err := models.AddTag(t.Name, t.State, t.CreatedBy); err != nil {
+		return err
+	}
+
+	t.clearCache()
+	return nil
+}
+
+// MissingNames 返回不存在的标签名称，名称不区分大小写
+func (t *Tag) MissingNames(names []string) ([]string, error) {
+	tags, err := models.GetTagsByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		existing[strings.ToLower(tag.Name)] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !existing[strings.ToLower(name)] {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing, nil
+}
+
+// GetOrAddByNames 按名称返回标签ID，不存在的标签以 t.CreatedBy 为创建人新建，名称不区分大小写
+func (t *Tag) GetOrAddByNames(names []string) ([]int, error) {
+	tags, err := models.GetTagsByNames(names)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		existing[strings.ToLower(tag.Name)] = true
+	}
+
+	added := false
+	for _, name := range names {
+		if existing[strings.ToLower(name)] {
+			continue
+		}
+		if err := models.AddTag(name, 1, t.CreatedBy); err != nil {
+			return nil, err
+		}
+		existing[strings.ToLower(name)] = true
+		added = true
+	}
+
+	if added {
+		t.clearCache()
+		if tags, err = models.GetTagsByNames(names); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		ids = append(ids, tag.ID)
+	}
+
+	return ids, nil
 }
 
 func (t *Tag) Edit() error {
@@ -45,15 +119,73 @@ func (t *Tag) Edit() error {
 		data["state"] = t.State
 	}
 
-	return models.EditTag(t.ID, data)
+	if err := models.EditTag(t.ID, data); err != nil {
+		return err
+	}
+
+	t.clearCache()
+	return nil
 }
 
 func (t *Tag) Delete() error {
-	return models.DeleteTag(t.ID)
+	if err := models.DeleteTag(t.ID); err != nil {
+		return err
+	}
+
+	t.clearCache()
+	return nil
+}
+
+// GetDeleted 获取回收站中的标签
+func (t *Tag) GetDeleted() ([]models.Tag, error) {
+	return models.GetDeletedTags(t.PageNum, t.PageSize)
+}
+
+func (t *Tag) CountDeleted() (int, error) {
+	return models.GetDeletedTagTotal()
+}
+
+// GetDeletedByID 获取回收站中的标签，不存在时 ID 为 0
+func (t *Tag) GetDeletedByID() (*models.Tag, error) {
+	return models.GetDeletedTag(t.ID)
+}
+
+// Restore 从回收站恢复标签
+func (t *Tag) Restore() error {
+	if err := models.RestoreTag(t.ID); err != nil {
+		return err
+	}
+
+	t.clearCache()
+	return nil
+}
+
+// Purge 彻底删除回收站中的标签
+func (t *Tag) Purge() error {
+	return models.PurgeTag(t.ID)
 }
 
 func (t *Tag) Count() (int, error) {
-	return models.GetTagTotal(t.getMaps())
+	var count int
+
+	cache := cache_service.Tag{
+		Name:  t.Name,
+		State: t.State,
+	}
+	key := cache.GetCountKey(store)
+	if err := gcache.GetObject(store, key, &count); err == nil {
+		return count, nil
+	} else if err != gcache.ErrNotFound {
+		logging.Info(err)
+	}
+
+	count, err := models.GetTagTotal(t.getMaps())
+	if err != nil {
+		return 0, err
+	}
+
+	gcache.SetObject(store, key, count, gcache.Jitter(time.Hour))
+	return count, nil
 }
 
 func (t *Tag) GetAll() ([]models.Tag, error) {
@@ -62,20 +194,17 @@ func (t *Tag) GetAll() ([]models.Tag, error) {
 	)
 
 	cache := cache_service.Tag{
+		Name:  t.Name,
 		State: t.State,
 
 		PageNum:  t.PageNum,
 		PageSize: t.PageSize,
 	}
-	key := cache.GetTagsKey()
-	if gredis.Exists(key) {
-		data, err := gredis.Get(key)
-		if err != nil {
-			logging.Info(err)
-		} else {
-			json.Unmarshal(data, &cacheTags)
-			return cacheTags, nil
-		}
+	key := cache.GetTagsKey(store)
+	if err := gcache.GetObject(store, key, &cacheTags); err == nil {
+		return cacheTags, nil
+	} else if err != gcache.ErrNotFound {
+		logging.Info(err)
 	}
 
 	tags, err := models.GetTags(t.PageNum, t.PageSize, t.getMaps())
@@ -83,7 +212,7 @@ func (t *Tag) GetAll() ([]models.Tag, error) {
 		return nil, err
 	}
 
-	gredis.Set(key, tags, 3600)
+	gcache.SetObject(store, key, tags, gcache.Jitter(time.Hour))
 	return tags, nil
 }
 
@@ -101,72 +230,42 @@ func (t *Tag) getMaps() map[string]interface{} {
 	return maps
 }
 
-func (t *Tag) Export() (string, error) {
+// Export 按 format 导出标签，返回导出的文件名，导入时按相同的列读取
+func (t *Tag) Export(format string) (string, error) {
 	tags, err := t.GetAll()
 	if err != nil {
 		return "", err
 	}
 
-	file := xlsx.NewFile()
-	sheet, err := file.AddSheet("标签信息")
-	if err != nil {
-		return "", err
-	}
-
-	titles := []string{"ID", "名称", "创建人", "创建时间", "修改人", "修改时间"}
-	row := sheet.AddRow()
-
-	var cell *xlsx.Cell
-	for _, title := range titles {
-		cell = row.AddCell()
-		cell.Value = title
+	table := export.Table{
+		Name: "标签信息",
+		Columns: []export.Column{
+			{Key: "id", Title: "ID"},
+			{Key: "name", Title: "名称"},
+			{Key: "created_by", Title: "创建人"},
+			{Key: "created_on", Title: "创建时间"},
+			{Key: "modified_by", Title: "修改人"},
+			{Key: "modified_on", Title: "修改时间"},
+		},
 	}
-
 	for _, v := range tags {
-		values := []string{
-			strconv.Itoa(v.ID),
+		table.Rows = append(table.Rows, []interface{}{
+			v.ID,
 			v.Name,
 			v.CreatedBy,
-			strconv.Itoa(v.CreatedOn),
+			export.FormatTime(v.CreatedOn),
 			v.ModifiedBy,
-			strconv.Itoa(v.ModifiedOn),
-		}
-
-		row = sheet.AddRow()
-		for _, value := range values {
-			cell = row.AddCell()
-			cell.Value = value
-		}
-	}
-
-	time := strconv.Itoa(int(time.Now().Unix()))
-	filename := "tags-" + time + ".xlsx"
-
-	fullPath := export.GetExcelFullPath() + filename
-	err = file.Save(fullPath)
-	if err != nil {
-		return "", err
+			export.FormatTime(v.ModifiedOn),
+		})
 	}
 
-	return filename, nil
+	return table.Save("tags", format)
 }
 
-func (t *Tag) Import(r io.Reader) error {
-	xlsx, err := excelize.OpenReader(r)
-	if err != nil {
-		return err
+// 写操作成功后清理缓存，清理失败只记录日志，不影响已经成功的写操作
+func (t *Tag) clearCache() {
+	cache := cache_service.Tag{}
+	if err := cache.Clear(store); err != nil {
+		logging.Warn(err)
 	}
-
-	rows := xlsx.GetRows("标签信息")
-	for irow, row := range rows {
-		if irow > 0 {
-			var data []string
-			for _, cell := range row {
-				data = append(data, cell)
-			}
-
-			models.AddTag(data[1], 1, data[2])
-		}
-	}
-	return nil
 }
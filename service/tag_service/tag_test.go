@@ -0,0 +1,159 @@
+package tag_service
+
+import (
+	"gin-blog/models"
+	"gin-blog/pkg/gcache"
+	"gin-blog/pkg/logging"
+	"gin-blog/pkg/setting"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupTest 使用内存数据库和内存缓存
+func setupTest(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	//日志路径相对于当前目录
+	wd, _ := os.Getwd()
+	dir, err := filepath.Rel(wd, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setting.AppSetting.RuntimeRootPath = dir + "/"
+	setting.AppSetting.LogSavePath = ""
+	setting.AppSetting.LogSaveName = "log"
+	setting.AppSetting.LogFileExt = "log"
+	setting.AppSetting.TimeFormat = "20060102"
+	logging.Setup()
+
+	conn, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	setting.DatabaseSetting.TablePrefix = "blog_"
+	models.SetDB(conn)
+	//内存数据库只存在于一个连接中
+	conn.DB().SetMaxOpenConns(1)
+	conn.LogMode(false)
+	if err := conn.AutoMigrate(&models.Tag{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	SetCache(gcache.NewMemory(0))
+	t.Cleanup(func() { SetCache(gcache.NewNone()) })
+
+	return conn
+}
+
+func listNames(t *testing.T) []string {
+	t.Helper()
+
+	tags, err := (&Tag{State: -1}).GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+
+	return names
+}
+
+func countTags(t *testing.T) int {
+	t.Helper()
+
+	count, err := (&Tag{State: -1}).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return count
+}
+
+func TestTagCacheFreshAfterWrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		write     func(t *testing.T, id int)
+		wantNames []string
+	}{
+		{
+			name: "add",
+			write: func(t *testing.T, id int) {
+				tag := Tag{Name: "rust", CreatedBy: "test", State: 1}
+				if err := tag.Add(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantNames: []string{"go", "python", "rust"},
+		},
+		{
+			name: "edit",
+			write: func(t *testing.T, id int) {
+				tag := Tag{ID: id, Name: "golang", ModifiedBy: "test", State: -1}
+				if err := tag.Edit(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantNames: []string{"golang", "python"},
+		},
+		{
+			name: "delete",
+			write: func(t *testing.T, id int) {
+				tag := Tag{ID: id}
+				if err := tag.Delete(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantNames: []string{"python"},
+		},
+		{
+			name: "import",
+			write: func(t *testing.T, id int) {
+				tag := Tag{CreatedBy: "test"}
+				report, err := tag.Import(strings.NewReader("名称\njava\nkotlin\n"), "csv", ImportOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !report.Committed || len(report.Created) != 2 {
+					t.Fatalf("Import() = %+v", report)
+				}
+			},
+			wantNames: []string{"go", "python", "java", "kotlin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTest(t)
+			for _, name := range []string{"go", "python"} {
+				if err := models.AddTag(name, 1, "test"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			tags, err := models.GetTagsByNames([]string{"go"})
+			if err != nil || len(tags) != 1 {
+				t.Fatal(tags, err)
+			}
+
+			//先读取一次，让列表和数量进入缓存
+			listNames(t)
+			countTags(t)
+
+			tt.write(t, tags[0].ID)
+
+			got := listNames(t)
+			if strings.Join(got, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("GetAll() = %v, want %v", got, tt.wantNames)
+			}
+			if count := countTags(t); count != len(tt.wantNames) {
+				t.Errorf("Count() = %d, want %d", count, len(tt.wantNames))
+			}
+		})
+	}
+}